@@ -4,6 +4,7 @@ import (
 	_ "backend/docs"
 	"backend/internal/config"
 	"backend/internal/pkg/app"
+	_ "time/tzdata" // time zones of stats must be available in minimal docker images
 )
 
 // @title                        URL Shortener App API
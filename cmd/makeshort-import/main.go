@@ -0,0 +1,84 @@
+// Command makeshort-import imports urls from export files of other shorteners into the database,
+// preserving their aliases and clicks counters. It reads the same config as makeshort-backend from CONFIG_PATH.
+//
+// Usage:
+//
+//	makeshort-import -format bitly|yourls|shlink [-file export.csv] [-user user-id] [-batch 1000]
+//
+// Not imported records are printed to stderr, one per line. Aliases which were cached as not found
+// become available after cache.negative_ttl.
+package main
+
+import (
+	"backend/internal/config"
+	"backend/internal/service/destination"
+	"backend/internal/service/importer"
+	"backend/internal/service/repository/postgres"
+	"backend/internal/service/repository/postgres/domain"
+	"backend/internal/service/repository/postgres/url"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	format := flag.String("format", "", "export format: bitly, yourls or shlink")
+	path := flag.String("file", "-", "export file, - for stdin")
+	userID := flag.String("user", "", "ID of user to assign urls to, urls are not assigned if empty")
+	batchSize := flag.Int("batch", importer.DefaultBatchSize, "count of urls saved in one transaction")
+	flag.Parse()
+
+	if *format == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+
+	var file io.Reader = os.Stdin
+	if *path != "-" {
+		f, err := os.Open(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "can't open file: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	db, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't connect to postgres: %s\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	urls, err := destination.New(cfg.Destination, cfg.BaseURL, domain.New(db), net.DefaultResolver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't create destination policy: %s\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	imp := importer.New(url.New(db), urls, *batchSize)
+	result, err := imp.Import(ctx, *userID, *format, file, importer.Hooks{
+		OnError: func(e importer.RowError) {
+			fmt.Fprintf(os.Stderr, "line %d: %s: alias %q, url %q\n", e.Line, e.Error, e.Alias, e.Url)
+		},
+	})
+
+	fmt.Printf("total: %d, imported: %d, conflicts: %d, invalid: %d\n",
+		result.Total, result.Imported, result.Conflicts, result.Invalid)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %s\n", err)
+		os.Exit(1)
+	}
+}
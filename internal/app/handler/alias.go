@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/lib/passthrough"
+	"backend/pkg/requestid"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSuggestionsLimit is the number of suggested aliases returned, if limit isn't provided.
+	DefaultSuggestionsLimit = 5
+	// MaxSuggestionsLimit is the maximum number of suggested aliases returned.
+	MaxSuggestionsLimit = 20
+)
+
+// GetAliasAvailability Checks whether an alias can be taken.
+// @Summary      Check alias availability
+// @Description  Checks whether an alias complies with the alias policy and isn't taken on the domain.
+// @Tags         url
+// @Param        alias  path  string true  "alias"
+// @Param        domain query string false "verified custom domain of authorized user, the default domain if empty"
+// @Produce      json
+// @Success      200  {object}    response.AliasAvailability
+// @Failure      400  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /url/alias/{alias}/availability [get]
+func (h *Handler) GetAliasAvailability(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetAliasAvailability"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	domainID, ok := h.getAliasDomainID(ctx, log)
+	if !ok {
+		return
+	}
+
+	alias, err := h.service.AliasPolicy.Normalize(ctx.Param("alias"))
+	if err != nil {
+		ctx.JSON(http.StatusOK, response.AliasAvailability{
+			Alias:  ctx.Param("alias"),
+			Reason: err.Error(),
+		})
+		return
+	}
+
+	taken, err := h.service.Repository.Url.GetTakenShortUrls(ctx, domainID, []string{alias})
+	if err != nil {
+		log.Error("error occurred while checking alias",
+			slog.String("alias", alias),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't check alias")
+		return
+	}
+
+	availability := response.AliasAvailability{
+		Alias:     alias,
+		Available: len(taken) == 0,
+	}
+	if !availability.Available {
+		availability.Reason = "alias already exists"
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// SuggestAliases Suggests available aliases for a destination.
+// @Summary      Suggest aliases
+// @Description  Suggests available human-friendly aliases derived from the domain and the path of destination, most preferred first.
+// @Tags         url
+// @Param        url    query string true  "destination url"
+// @Param        domain query string false "verified custom domain of authorized user, the default domain if empty"
+// @Param        limit  query int    false "number of aliases, 5 by default, up to 20"
+// @Produce      json
+// @Success      200  {object}    response.AliasSuggestions
+// @Failure      400  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /url/alias/suggest [get]
+func (h *Handler) SuggestAliases(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.SuggestAliases"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	destination, err := neturl.ParseRequestURI(strings.ReplaceAll(ctx.Query("url"), passthrough.PathPlaceholder, ""))
+	if err != nil || destination.Host == "" {
+		response.SendError(ctx, http.StatusBadRequest, "url is invalid")
+		return
+	}
+
+	limit := DefaultSuggestionsLimit
+	if value := ctx.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxSuggestionsLimit {
+			response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", MaxSuggestionsLimit))
+			return
+		}
+	}
+
+	domainID, ok := h.getAliasDomainID(ctx, log)
+	if !ok {
+		return
+	}
+
+	candidates := h.service.AliasPolicy.Suggest(destination)
+	suggestions := make([]string, 0, limit)
+
+	if len(candidates) > 0 {
+		taken, err := h.service.Repository.Url.GetTakenShortUrls(ctx, domainID, candidates)
+		if err != nil {
+			log.Error("error occurred while checking suggested aliases",
+				slog.String("url", destination.String()),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't suggest aliases")
+			return
+		}
+
+		isTaken := make(map[string]bool, len(taken))
+		for _, alias := range taken {
+			isTaken[alias] = true
+		}
+		for _, alias := range candidates {
+			if len(suggestions) == limit {
+				break
+			}
+			if !isTaken[alias] {
+				suggestions = append(suggestions, alias)
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response.AliasSuggestions{Suggestions: suggestions})
+}
+
+// getAliasDomainID returns ID of the custom domain from domain query parameter, or an empty string for the default domain.
+// If the domain can't be used by the user, the function sends an error response and returns false.
+func (h *Handler) getAliasDomainID(ctx *gin.Context, log *slog.Logger) (string, bool) {
+	host := ctx.Query("domain")
+	if host == "" {
+		return "", true
+	}
+
+	d, ok := h.getUserDomain(ctx, log, ctx.GetString(middleware.ContextUserID), host)
+	return d.ID, ok
+}
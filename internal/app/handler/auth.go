@@ -51,6 +51,12 @@ func (h *Handler) Register(ctx *gin.Context) {
 	passwordHash := h.service.Hasher.Create(body.Password)
 
 	id, err := h.service.Repository.User.Create(ctx, body.Email, body.Username, passwordHash)
+	if errors.Is(err, repository.ErrUserAlreadyExists) {
+		field := repository.ConflictField(err)
+		log.Debug("user already exists", slog.String("field", field))
+		response.SendConflictError(ctx, field+" already taken", field)
+		return
+	}
 	if err != nil {
 		log.Error("error occurred while creating user", sl.Err(err))
 		response.SendError(ctx, http.StatusInternalServerError, "can't create user")
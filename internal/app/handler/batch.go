@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/destination"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/pkg/requestid"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// MaxBatchSize is the maximum count of urls created by one batch request.
+const MaxBatchSize = 1000
+
+// MaxBatchBodySize is the maximum size of batch request body, enough for MaxBatchSize rows with the longest urls.
+const MaxBatchBodySize = 4 << 20
+
+// MIMECSV is a MIME type of CSV body.
+const MIMECSV = "text/csv"
+
+var errBatchTooLarge = fmt.Errorf("batch contains more than %d rows", MaxBatchSize)
+
+// CreateUrlsBatch  Creates many URLs in database in one transaction.
+// @Summary      Create URLs batch
+// @Description  Creates many URLs in database, assigned to user. Rows are accepted as a JSON array, CSV body or CSV file uploaded as "file" form field.
+// @Description  CSV has url and alias columns, optionally with a header. Every row gets its own result, so invalid rows and alias conflicts don't fail the whole batch.
+// @Description  Rows without alias get generated ones, rows with colliding generated aliases are retried in next transactions.
+// @Security     AccessToken
+// @Tags         url
+// @Accept       json,text/csv,multipart/form-data
+// @Produce      json
+// @Param        input body       []request.BatchUrl false "Urls data"
+// @Param        file  formData   file               false "CSV file with urls"
+// @Success      200  {object}    response.UrlBatch
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      413  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /url/batch       [post]
+func (h *Handler) CreateUrlsBatch(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.CreateUrlsBatch"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxBatchBodySize)
+
+	rows, err := readBatchRows(ctx)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		log.Debug("batch body is too large")
+		response.SendError(ctx, http.StatusRequestEntityTooLarge, "batch is too large")
+		return
+	}
+	if errors.Is(err, errBatchTooLarge) {
+		log.Debug("batch is too large")
+		response.SendError(ctx, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		log.Debug("error occurred while reading batch rows", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+	if len(rows) == 0 {
+		response.SendError(ctx, http.StatusBadRequest, "batch is empty")
+		return
+	}
+
+	results := make([]response.BatchRow, len(rows))
+	dtos := make([]repoUrl.DTO, 0, len(rows))
+	dtoRows := make([]int, 0, len(rows)) // index of result for every DTO
+
+	for i, row := range rows {
+		results[i] = response.BatchRow{Row: i + 1, Url: row.Url, Alias: row.Alias}
+
+		parsedUrl, isUrlValid := validateUrl(row.Url)
+		if !isUrlValid {
+			results[i].Error = "url is invalid"
+			continue
+		}
+		if len(parsedUrl) > MaxUrlLength {
+			results[i].Error = "url is too long"
+			continue
+		}
+
+		err = h.service.Destination.Check(ctx, parsedUrl)
+		if destination.IsViolation(err) {
+			results[i].Error = err.Error()
+			continue
+		}
+		if err != nil {
+			log.Error("error occurred while checking url",
+				slog.String("url", parsedUrl),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't save urls")
+			return
+		}
+
+		alias := row.Alias
+		if alias != "" {
+			if alias, err = h.service.AliasPolicy.Normalize(alias); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+		}
+
+		dtos = append(dtos, repoUrl.DTO{
+			LongURL:  parsedUrl,
+			ShortURL: alias,
+		})
+		dtoRows = append(dtoRows, i)
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	// rows without alias get generated ones, rows with colliding generated aliases are retried in the next round
+	aliases := make([]string, 0, len(dtos))
+	for attempt := 1; len(dtos) > 0; attempt++ {
+		for j := range dtos {
+			if rows[dtoRows[j]].Alias != "" {
+				continue
+			}
+			if dtos[j].ShortURL, err = h.service.Alias.Generate(ctx); err != nil {
+				log.Error("error occurred while generating alias", sl.Err(err))
+				response.SendError(ctx, http.StatusInternalServerError, "can't save urls")
+				return
+			}
+		}
+
+		created, err := h.service.Repository.Url.CreateBatch(ctx, userID, dtos)
+		if err != nil {
+			log.Error("error occurred while saving urls batch to database",
+				slog.Int("rows", len(rows)),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't save urls")
+			return
+		}
+
+		var retryDtos []repoUrl.DTO
+		var retryRows []int
+		for j, res := range created {
+			row := &results[dtoRows[j]]
+			row.Alias = dtos[j].ShortURL
+
+			generated := rows[dtoRows[j]].Alias == ""
+			if generated {
+				h.service.Alias.Observe(res.Err)
+			}
+
+			if repoUrl.IsErrShortUrlAlreadyExists(res.Err) {
+				if generated && attempt < h.service.Alias.MaxAttempts() {
+					retryDtos = append(retryDtos, dtos[j])
+					retryRows = append(retryRows, dtoRows[j])
+					continue
+				}
+				row.Error = "alias already exists"
+				continue
+			}
+			if res.Err != nil {
+				log.Debug("url of batch row can't be saved",
+					slog.Int("row", row.Row),
+					sl.Err(res.Err),
+				)
+				row.Error = "url can't be saved"
+				continue
+			}
+			row.ID = res.ID
+			aliases = append(aliases, row.Alias)
+		}
+		dtos, dtoRows = retryDtos, retryRows
+	}
+
+	if len(aliases) > 0 {
+		h.invalidateUrlCache(ctx, log, "", aliases...)
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlBatch{
+		Created: len(aliases),
+		Failed:  len(rows) - len(aliases),
+		Results: results,
+	})
+	log.Info("urls batch saved",
+		slog.Int("rows", len(rows)),
+		slog.Int("created", len(aliases)),
+	)
+}
+
+// readBatchRows reads batch rows from request body: a CSV file from multipart form, a CSV body or a JSON array.
+func readBatchRows(ctx *gin.Context) ([]request.BatchUrl, error) {
+	switch ctx.ContentType() {
+	case gin.MIMEMultipartPOSTForm:
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return readCsvBatchRows(file)
+	case MIMECSV:
+		return readCsvBatchRows(ctx.Request.Body)
+	default:
+		var rows []request.BatchUrl
+		if err := ctx.ShouldBindJSON(&rows); err != nil {
+			return nil, err
+		}
+		if len(rows) > MaxBatchSize {
+			return nil, errBatchTooLarge
+		}
+		return rows, nil
+	}
+}
+
+// readCsvBatchRows reads batch rows from CSV with url and alias columns.
+// If the first record contains a "url" column, it is treated as a header defining order of columns.
+func readCsvBatchRows(r io.Reader) ([]request.BatchUrl, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	urlColumn, aliasColumn := 0, 1
+
+	var rows []request.BatchUrl
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if first {
+			if header, ok := csvHeader(record); ok {
+				urlColumn, aliasColumn = header["url"], -1
+				if column, ok := header["alias"]; ok {
+					aliasColumn = column
+				}
+				continue
+			}
+		}
+
+		if len(rows) == MaxBatchSize {
+			return nil, errBatchTooLarge
+		}
+
+		var row request.BatchUrl
+		if urlColumn < len(record) {
+			row.Url = strings.TrimSpace(record[urlColumn])
+		}
+		if aliasColumn >= 0 && aliasColumn < len(record) {
+			row.Alias = strings.TrimSpace(record[aliasColumn])
+		}
+		rows = append(rows, row)
+	}
+}
+
+// csvHeader returns columns of CSV header mapped by their lowercase names, if the record is a header.
+func csvHeader(record []string) (map[string]int, bool) {
+	header := make(map[string]int, len(record))
+	for i, name := range record {
+		header[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	_, ok := header["url"]
+	return header, ok
+}
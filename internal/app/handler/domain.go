@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/domain"
+	"backend/internal/service/repository"
+	repoDomain "backend/internal/service/repository/postgres/domain"
+	"backend/pkg/requestid"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// verifyDomainTimeout is the maximum duration of verification record lookup.
+const verifyDomainTimeout = 5 * time.Second
+
+// CreateDomain  Registers a custom domain of user.
+// @Summary      Create domain
+// @Description  Registers a custom domain of user. The domain serves urls after verification by DNS TXT record.
+// @Security     AccessToken
+// @Tags         domain
+// @Accept       json
+// @Produce      json
+// @Param        input body       request.DomainCreate true "Domain data"
+// @Success      201  {object}    response.Domain
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      409  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /domain          [post]
+func (h *Handler) CreateDomain(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.CreateDomain"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.DomainCreate
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	host, err := domain.NormalizeHost(body.Host)
+	if err != nil {
+		log.Debug("host is invalid", slog.String("host", body.Host))
+		response.SendError(ctx, http.StatusBadRequest, "host is invalid")
+		return
+	}
+
+	token, err := domain.GenerateToken()
+	if err != nil {
+		log.Error("error occurred while generating verification token", sl.Err(err))
+		response.SendError(ctx, http.StatusInternalServerError, "can't create domain")
+		return
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	// not verified claims don't take the host, so it's free until somebody verifies it
+	_, err = h.service.Repository.Domain.GetVerifiedByHost(ctx, host)
+	if err == nil {
+		log.Debug("domain is verified by another user", slog.String("host", host))
+		response.SendConflictError(ctx, "domain already exists", "host")
+		return
+	}
+	if !repoDomain.IsErrDomainNotFound(err) {
+		log.Error("error occurred while getting domain",
+			slog.String("host", host),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't create domain")
+		return
+	}
+
+	d, err := h.service.Repository.Domain.Create(ctx, userID, host, token)
+	if errors.Is(err, repository.ErrDomainAlreadyExists) {
+		log.Debug("domain already exists", slog.String("host", host))
+		response.SendConflictError(ctx, "domain already exists", repository.ConflictField(err))
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while creating domain",
+			slog.String("host", host),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't create domain")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toDomainResponse(d))
+	log.Info("domain created",
+		slog.String("id", d.ID),
+		slog.String("host", host),
+		slog.String("user_id", userID),
+	)
+}
+
+// GetDomains    Gets all custom domains of user.
+// @Summary      Get domains
+// @Description  Get all custom domains of user
+// @Security     AccessToken
+// @Tags         domain
+// @Produce      json
+// @Success      200  {array}     response.Domain
+// @Failure      401  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /domain          [get]
+func (h *Handler) GetDomains(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetDomains"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	domains, err := h.service.Repository.Domain.GetListByUser(ctx, userID)
+	if err != nil {
+		log.Error("error occurred while getting domains",
+			slog.String("user_id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get domains")
+		return
+	}
+
+	result := make([]response.Domain, len(domains))
+	for i, d := range domains {
+		result[i] = toDomainResponse(d)
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// VerifyDomain  Verifies a custom domain by DNS TXT record.
+// @Summary      Verify domain
+// @Description  Checks that verification TXT record of the domain exists, and marks the domain as verified
+// @Security     AccessToken
+// @Tags         domain
+// @Param        id path string true "id"
+// @Produce      json
+// @Success      200  {object}           response.Domain
+// @Failure      400  {object}           response.Error
+// @Failure      401  {object}           response.Error
+// @Failure      403  {object}           response.Error
+// @Failure      404  {object}           response.Error
+// @Failure      409  {object}           response.Error
+// @Failure      500  {object}           response.Error
+// @Router       /domain/{id}/verify     [post]
+func (h *Handler) VerifyDomain(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.VerifyDomain"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	domainID := ctx.Param("id")
+
+	d, err := h.service.Repository.Domain.GetByID(ctx, domainID)
+	if err != nil {
+		log.Error("error occurred while getting domain",
+			slog.String("id", domainID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get domain")
+		return
+	}
+
+	if !d.IsVerified() {
+		lookupCtx, cancel := context.WithTimeout(ctx, verifyDomainTimeout)
+		verified, err := h.service.DomainVerifier.Verify(lookupCtx, d.Host, d.VerificationToken)
+		cancel()
+		if err != nil {
+			log.Error("error occurred while looking up verification record",
+				slog.String("host", d.Host),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't look up verification record")
+			return
+		}
+		if !verified {
+			log.Debug("verification record not found", slog.String("host", d.Host))
+			response.SendError(ctx, http.StatusBadRequest, "verification record not found, it may take some time to propagate")
+			return
+		}
+
+		d, err = h.service.Repository.Domain.SetVerified(ctx, domainID)
+		if errors.Is(err, repository.ErrDomainAlreadyExists) {
+			log.Debug("domain is verified by another user", slog.String("id", domainID))
+			response.SendConflictError(ctx, "domain is already verified by another user", repository.ConflictField(err))
+			return
+		}
+		if err != nil {
+			log.Error("error occurred while verifying domain",
+				slog.String("id", domainID),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't verify domain")
+			return
+		}
+
+		h.invalidateDomainCache(ctx, log, d.Host)
+
+		log.Info("domain verified",
+			slog.String("id", d.ID),
+			slog.String("host", d.Host),
+		)
+	}
+
+	ctx.JSON(http.StatusOK, toDomainResponse(d))
+}
+
+// DeleteDomain  Deletes a custom domain with all its URLs.
+// @Summary      Delete domain
+// @Description  Deletes a custom domain of user with all its URLs
+// @Security     AccessToken
+// @Tags         domain
+// @Param        id path string true "id"
+// @Success      200  {integer}     integer 1
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /domain/{id}       [delete]
+func (h *Handler) DeleteDomain(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.DeleteDomain"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	domainID := ctx.Param("id")
+
+	d, err := h.service.Repository.Domain.GetByID(ctx, domainID)
+	if err != nil {
+		log.Error("error occurred while getting domain",
+			slog.String("id", domainID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get domain")
+		return
+	}
+
+	err = h.service.Repository.Domain.Delete(ctx, domainID)
+	if repoDomain.IsErrDomainNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "no domain to delete")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while deleting domain",
+			slog.String("id", domainID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't delete domain")
+		return
+	}
+
+	h.invalidateDomainCache(ctx, log, d.Host)
+
+	ctx.Status(http.StatusOK)
+	log.Info("domain deleted",
+		slog.String("id", domainID),
+		slog.String("host", d.Host),
+	)
+}
+
+// getUserDomain gets a verified custom domain of the user by its host.
+// If the domain can't be used by the user, the function sends an error response and returns false.
+func (h *Handler) getUserDomain(ctx *gin.Context, log *slog.Logger, userID string, rawHost string) (repoDomain.Domain, bool) {
+	host, err := domain.NormalizeHost(rawHost)
+	if err != nil {
+		log.Debug("domain is invalid", slog.String("domain", rawHost))
+		response.SendError(ctx, http.StatusBadRequest, "domain is invalid")
+		return repoDomain.Domain{}, false
+	}
+
+	var d repoDomain.Domain
+	err = repoDomain.ErrDomainNotFound
+	if userID != "" {
+		d, err = h.service.Repository.Domain.GetByUserAndHost(ctx, userID, host)
+	}
+	if repoDomain.IsErrDomainNotFound(err) {
+		// the host may be verified by another user
+		d, err = h.service.Repository.Domain.GetVerifiedByHost(ctx, host)
+	}
+	if repoDomain.IsErrDomainNotFound(err) {
+		log.Debug("domain not found", slog.String("domain", host))
+		response.SendError(ctx, http.StatusBadRequest, "domain not found")
+		return repoDomain.Domain{}, false
+	}
+	if err != nil {
+		log.Error("error occurred while getting domain",
+			slog.String("domain", host),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get domain")
+		return repoDomain.Domain{}, false
+	}
+
+	if userID == "" || d.UserID != userID {
+		log.Debug("not domain's owner",
+			slog.String("domain", host),
+			slog.String("user_id", userID),
+		)
+		response.SendError(ctx, http.StatusForbidden, "domain is not yours")
+		return repoDomain.Domain{}, false
+	}
+
+	if !d.IsVerified() {
+		response.SendError(ctx, http.StatusBadRequest, "domain is not verified")
+		return repoDomain.Domain{}, false
+	}
+
+	return d, true
+}
+
+// invalidateDomainCache deletes cached domain by its host, so redirects on this host use fresh domain state.
+func (h *Handler) invalidateDomainCache(ctx *gin.Context, log *slog.Logger, host string) {
+	err := h.service.Repository.UrlCache.DeleteDomain(ctx, host)
+	if err != nil {
+		log.Error("error occurred while invalidating domain cache",
+			slog.String("host", host),
+			sl.Err(err),
+		)
+	}
+}
+
+// toDomainResponse converts a domain from repository to response one.
+func toDomainResponse(d repoDomain.Domain) response.Domain {
+	return response.Domain{
+		ID:         d.ID,
+		Host:       d.Host,
+		Verified:   d.IsVerified(),
+		VerifiedAt: d.VerifiedAt,
+		VerificationRecord: response.DomainRecord{
+			Type:  "TXT",
+			Name:  domain.RecordName(d.Host),
+			Value: domain.RecordValue(d.VerificationToken),
+		},
+	}
+}
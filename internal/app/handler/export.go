@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/pkg/requestid"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Export formats of urls.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// exportFlushRows is a count of exported urls after which the response is flushed to the client.
+const exportFlushRows = 500
+
+// urlEncoder writes exported urls in some format.
+type urlEncoder interface {
+	Encode(u response.UrlExport) error
+	Close() error
+}
+
+// ExportUserUrls  Streams all urls of user in CSV, JSON or NDJSON format.
+// @Summary      Export URLs
+// @Security     AccessToken
+// @Description  Streams all URLs created by user with all their fields, ordered by creation date
+// @Tags         user
+// @Param        id     path  string true  "id"
+// @Param        format query string false "Export format: csv, json (default) or ndjson"
+// @Produce      json,text/csv,application/x-ndjson
+// @Success      200  {array}           response.UrlExport
+// @Failure      400  {object}          response.Error
+// @Failure      401  {object}          response.Error
+// @Failure      403  {object}          response.Error
+// @Failure      500  {object}          response.Error
+// @Router       /user/{id}/urls/export [get]
+func (h *Handler) ExportUserUrls(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.ExportUserUrls"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	format := ctx.DefaultQuery("format", ExportFormatJSON)
+
+	var contentType string
+	switch format {
+	case ExportFormatCSV:
+		contentType = MIMECSV
+	case ExportFormatJSON:
+		contentType = gin.MIMEJSON
+	case ExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		response.SendError(ctx, http.StatusBadRequest, "format must be csv, json or ndjson")
+		return
+	}
+
+	// export of many urls may take longer than the server write timeout
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("can't reset write deadline", sl.Err(err))
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	ctx.Header("Content-Type", contentType+"; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+	ctx.Status(http.StatusOK)
+
+	w := bufio.NewWriter(ctx.Writer)
+	encoder := newUrlEncoder(format, w)
+
+	var count int
+	err := h.service.Repository.User.ExportUrls(ctx, userID, func(u repoUrl.URL) error {
+		if err := encoder.Encode(toUrlExport(u)); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		// the status is already sent, so the client gets a truncated body
+		log.Error("error occurred while exporting urls",
+			slog.String("user_id", userID),
+			slog.Int("exported", count),
+			sl.Err(err),
+		)
+		ctx.Abort()
+		return
+	}
+
+	log.Info("urls exported",
+		slog.String("user_id", userID),
+		slog.String("format", format),
+		slog.Int("exported", count),
+	)
+}
+
+// toUrlExport converts an url from repository to export one.
+func toUrlExport(u repoUrl.URL) response.UrlExport {
+	e := response.UrlExport{
+		ID:               u.ID,
+		Url:              u.LongURL,
+		Alias:            u.ShortURL,
+		Redirects:        u.Redirects,
+		CreatedAt:        u.CreatedAt,
+		ExpiresAt:        u.ExpiresAt,
+		MaxRedirects:     u.MaxRedirects,
+		Protected:        u.IsPasswordProtected(),
+		RedirectCode:     u.GetRedirectCode(),
+		CacheControl:     u.CacheControl,
+		ReferrerPolicy:   u.ReferrerPolicy,
+		RobotsTag:        u.RobotsTag,
+		QueryPassthrough: u.QueryPassthrough,
+		Title:            u.Title,
+		Notes:            u.Notes,
+		Rules:            toRuleResponses(u.Rules),
+		Variants:         toVariantsResponse(u.Variants, u.VariantsSticky).Variants,
+		VariantsSticky:   u.VariantsSticky,
+		Tags:             []string(u.Tags),
+	}
+	if e.Tags == nil {
+		e.Tags = []string{}
+	}
+	if u.UserID != nil {
+		e.UserID = *u.UserID
+	}
+	if u.Domain != nil {
+		e.Domain = *u.Domain
+	}
+	if u.FolderID != nil {
+		e.FolderID = *u.FolderID
+	}
+	return e
+}
+
+// newUrlEncoder returns an encoder of urls in provided format.
+func newUrlEncoder(format string, w io.Writer) urlEncoder {
+	switch format {
+	case ExportFormatCSV:
+		return &csvUrlEncoder{w: csv.NewWriter(w)}
+	case ExportFormatNDJSON:
+		return &ndjsonUrlEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &jsonUrlEncoder{w: w, enc: json.NewEncoder(w)}
+	}
+}
+
+// csvExportHeader is a header of exported CSV, in order of response.UrlExport fields.
+var csvExportHeader = []string{"id", "user_id", "url", "alias", "domain", "redirects", "created_at", "expires_at", "max_redirects", "password_protected", "redirect_code", "cache_control", "referrer_policy", "robots_tag", "query_passthrough", "title", "notes", "rules", "variants", "variants_sticky", "folder_id", "tags"}
+
+// csvUrlEncoder writes urls as CSV rows with a header.
+type csvUrlEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvUrlEncoder) Encode(u response.UrlExport) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	var expiresAt, maxRedirects string
+	if u.ExpiresAt != nil {
+		expiresAt = u.ExpiresAt.Format(time.RFC3339)
+	}
+	if u.MaxRedirects != nil {
+		maxRedirects = strconv.Itoa(*u.MaxRedirects)
+	}
+
+	rules, err := csvJSONValue(u.Rules, len(u.Rules))
+	if err != nil {
+		return err
+	}
+	variants, err := csvJSONValue(u.Variants, len(u.Variants))
+	if err != nil {
+		return err
+	}
+	tags, err := csvJSONValue(u.Tags, len(u.Tags))
+	if err != nil {
+		return err
+	}
+
+	return e.w.Write([]string{
+		u.ID,
+		u.UserID,
+		u.Url,
+		u.Alias,
+		u.Domain,
+		strconv.Itoa(u.Redirects),
+		u.CreatedAt.Format(time.RFC3339),
+		expiresAt,
+		maxRedirects,
+		strconv.FormatBool(u.Protected),
+		strconv.Itoa(u.RedirectCode),
+		u.CacheControl,
+		u.ReferrerPolicy,
+		u.RobotsTag,
+		u.QueryPassthrough,
+		u.Title,
+		u.Notes,
+		rules,
+		variants,
+		strconv.FormatBool(u.VariantsSticky),
+		u.FolderID,
+		tags,
+	})
+}
+
+// csvJSONValue encodes a list field of url as a JSON array for CSV column, or an empty string if the list is empty.
+func csvJSONValue(v any, length int) (string, error) {
+	if length == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func (e *csvUrlEncoder) Close() error {
+	// the header is written even without urls
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvUrlEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.w.Write(csvExportHeader)
+}
+
+// jsonUrlEncoder writes urls as elements of one JSON array.
+type jsonUrlEncoder struct {
+	w       io.Writer
+	enc     *json.Encoder
+	started bool
+}
+
+func (e *jsonUrlEncoder) Encode(u response.UrlExport) error {
+	separator := ","
+	if !e.started {
+		e.started = true
+		separator = "["
+	}
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+	return e.enc.Encode(u)
+}
+
+func (e *jsonUrlEncoder) Close() error {
+	closing := "]"
+	if !e.started {
+		closing = "[]"
+	}
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
+
+// ndjsonUrlEncoder writes urls as JSON objects, one per line.
+type ndjsonUrlEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonUrlEncoder) Encode(u response.UrlExport) error {
+	return e.enc.Encode(u)
+}
+
+func (e *ndjsonUrlEncoder) Close() error {
+	return nil
+}
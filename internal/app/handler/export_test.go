@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"backend/internal/service/repository/postgres/url"
+	"backend/internal/service/rules"
+	"backend/internal/service/variants"
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCsvUrlEncoder(t *testing.T) {
+	userID, folderID := "user-1", "folder-1"
+	u := url.URL{
+		ID:               "url-1",
+		UserID:           &userID,
+		LongURL:          "https://example.com",
+		ShortURL:         "abc",
+		Redirects:        3,
+		CreatedAt:        time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		QueryPassthrough: "off",
+		Rules:            rules.List{{ID: "r1", OS: []string{"iOS"}, Url: "https://apps.apple.com"}},
+		Variants:         variants.List{{ID: "a", Url: "https://example.com/a", Weight: 70}, {ID: "b", Url: "https://example.com/b", Weight: 30}},
+		VariantsSticky:   true,
+		FolderID:         &folderID,
+		Tags:             []string{"docs", "work"},
+	}
+
+	var buf bytes.Buffer
+	encoder := newUrlEncoder(ExportFormatCSV, &buf)
+	if err := encoder.Encode(toUrlExport(u)); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := encoder.Encode(toUrlExport(url.URL{ID: "url-2", QueryPassthrough: "off"})); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV is invalid: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want header and 2 urls", len(records))
+	}
+	if !reflect.DeepEqual(records[0], csvExportHeader) {
+		t.Errorf("header = %v, want %v", records[0], csvExportHeader)
+	}
+
+	want := map[string][2]string{
+		"id":        {"url-1", "url-2"},
+		"user_id":   {"user-1", ""},
+		"url":       {"https://example.com", ""},
+		"alias":     {"abc", ""},
+		"redirects": {"3", "0"},
+		"rules":     {`[{"id":"r1","os":["iOS"],"url":"https://apps.apple.com"}]`, ""},
+		"variants": {
+			`[{"id":"a","url":"https://example.com/a","weight":70},{"id":"b","url":"https://example.com/b","weight":30}]`,
+			"",
+		},
+		"variants_sticky": {"true", "false"},
+		"folder_id":       {"folder-1", ""},
+		"tags":            {`["docs","work"]`, ""},
+	}
+	for column, values := range want {
+		i := indexOf(records[0], column)
+		if i < 0 {
+			t.Errorf("column %q is missing", column)
+			continue
+		}
+		for row, value := range values {
+			if got := records[row+1][i]; got != value {
+				t.Errorf("row %d column %q = %q, want %q", row+1, column, got, value)
+			}
+		}
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
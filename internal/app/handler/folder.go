@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/repository"
+	repoFolder "backend/internal/service/repository/postgres/folder"
+	"backend/pkg/requestid"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxFolderNameLength is the maximum length of folder name in characters.
+const MaxFolderNameLength = 100
+
+// CreateFolder  Creates a folder of user.
+// @Summary      Create folder
+// @Description  Creates a folder of user. Folders can be nested, names are unique within the parent folder.
+// @Security     AccessToken
+// @Tags         folder
+// @Accept       json
+// @Produce      json
+// @Param        input body       request.FolderCreate true "Folder data"
+// @Success      201  {object}    response.Folder
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      409  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /folder          [post]
+func (h *Handler) CreateFolder(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.CreateFolder"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.FolderCreate
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	name, ok := normalizeFolderName(body.Name)
+	if !ok {
+		response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("name must be 1-%d characters", MaxFolderNameLength))
+		return
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	if body.ParentID != "" {
+		if _, ok = h.getUserFolder(ctx, log, userID, body.ParentID); !ok {
+			return
+		}
+	}
+
+	folder, err := h.service.Repository.Folder.Create(ctx, userID, body.ParentID, name)
+	if errors.Is(err, repository.ErrFolderAlreadyExists) {
+		response.SendConflictError(ctx, "folder already exists", repository.ConflictField(err))
+		return
+	}
+	if repoFolder.IsErrParentNotFound(err) {
+		response.SendError(ctx, http.StatusBadRequest, "folder not found")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while creating folder",
+			slog.String("name", name),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't create folder")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toFolderResponse(folder))
+	log.Info("folder created",
+		slog.String("id", folder.ID),
+		slog.String("name", folder.Name),
+	)
+}
+
+// GetFolders    Returns folders of user.
+// @Summary      Get folders
+// @Description  Returns all folders of user as a flat list with counts of their urls, sorted by name.
+// @Description  The hierarchy is built by parent IDs.
+// @Security     AccessToken
+// @Tags         folder
+// @Produce      json
+// @Success      200  {array}     response.Folder
+// @Failure      401  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /folder          [get]
+func (h *Handler) GetFolders(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetFolders"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	folders, err := h.service.Repository.Folder.GetListByUser(ctx, userID)
+	if err != nil {
+		log.Error("error occurred while getting folders",
+			slog.String("user_id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get folders")
+		return
+	}
+
+	result := make([]response.Folder, len(folders))
+	for i, folder := range folders {
+		result[i] = toFolderResponse(folder)
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// UpdateFolder  Renames or moves a folder.
+// @Summary      Update folder
+// @Description  Renames a folder or moves it with its subfolders into another folder. Empty parent ID moves it to the top level.
+// @Security     AccessToken
+// @Tags         folder
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body       request.FolderUpdate true "Folder data"
+// @Success      200  {object}    response.Folder
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      409  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /folder/{id}     [patch]
+func (h *Handler) UpdateFolder(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.UpdateFolder"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.FolderUpdate
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	if body.Name != nil {
+		name, ok := normalizeFolderName(*body.Name)
+		if !ok {
+			response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("name must be 1-%d characters", MaxFolderNameLength))
+			return
+		}
+		body.Name = &name
+	}
+
+	folderID := ctx.Param("id")
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	if body.ParentID != nil && *body.ParentID != "" {
+		if _, ok := h.getUserFolder(ctx, log, userID, *body.ParentID); !ok {
+			return
+		}
+	}
+
+	folder, err := h.service.Repository.Folder.Update(ctx, folderID, repoFolder.DTO{
+		Name:     body.Name,
+		ParentID: body.ParentID,
+	})
+	if repoFolder.IsErrFolderNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "folder with this id not found")
+		return
+	}
+	if errors.Is(err, repository.ErrFolderAlreadyExists) {
+		response.SendConflictError(ctx, "folder already exists", repository.ConflictField(err))
+		return
+	}
+	if repoFolder.IsErrParentNotFound(err) {
+		response.SendError(ctx, http.StatusBadRequest, "folder not found")
+		return
+	}
+	if repoFolder.IsErrCyclicMove(err) {
+		response.SendError(ctx, http.StatusBadRequest, "folder can't be moved into itself or its subfolder")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while updating folder",
+			slog.String("id", folderID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't update folder")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toFolderResponse(folder))
+}
+
+// DeleteFolder  Deletes a folder.
+// @Summary      Delete folder
+// @Description  Deletes a folder with all its subfolders. Their urls aren't deleted, they are moved to no folder.
+// @Security     AccessToken
+// @Tags         folder
+// @Param        id path string true "id"
+// @Success      200
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /folder/{id}     [delete]
+func (h *Handler) DeleteFolder(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.DeleteFolder"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	folderID := ctx.Param("id")
+
+	err := h.service.Repository.Folder.Delete(ctx, folderID)
+	if repoFolder.IsErrFolderNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "no folder to delete")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while deleting folder",
+			slog.String("id", folderID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't delete folder")
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+	log.Info("folder deleted",
+		slog.String("id", folderID),
+	)
+}
+
+// MoveUrlsToFolder Moves many URLs into a folder at once.
+// @Summary         Move URLs to folder
+// @Description     Moves many URLs of user into a folder at once. URLs of other users are skipped.
+// @Security        AccessToken
+// @Tags            folder
+// @Accept          json
+// @Produce         json
+// @Param           id    path string true "id"
+// @Param           input body       request.UrlIDs true "IDs of URLs, up to 1000"
+// @Success         200  {object}    response.UrlsAffected
+// @Failure         400  {object}    response.Error
+// @Failure         401  {object}    response.Error
+// @Failure         403  {object}    response.Error
+// @Failure         404  {object}    response.Error
+// @Failure         500  {object}    response.Error
+// @Router          /folder/{id}/urls [post]
+func (h *Handler) MoveUrlsToFolder(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.MoveUrlsToFolder"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	ids, ok := bindUrlIDs(ctx, log)
+	if !ok {
+		return
+	}
+
+	folderID := ctx.Param("id")
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	affected, err := h.service.Repository.Folder.MoveUrls(ctx, folderID, userID, ids)
+	if err != nil {
+		log.Error("error occurred while moving urls to folder",
+			slog.String("id", folderID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't move urls")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlsAffected{Affected: affected})
+	log.Info("urls moved to folder",
+		slog.String("id", folderID),
+		slog.Int64("affected", affected),
+	)
+}
+
+// getUserFolder gets a folder by ID and checks, that the user owns it.
+// If the folder can't be used by the user, the function sends an error response and returns false.
+func (h *Handler) getUserFolder(ctx *gin.Context, log *slog.Logger, userID string, folderID string) (repoFolder.Folder, bool) {
+	if _, err := uuid.Parse(folderID); err != nil {
+		response.SendError(ctx, http.StatusBadRequest, "folder is invalid")
+		return repoFolder.Folder{}, false
+	}
+
+	folder, err := h.service.Repository.Folder.GetByID(ctx, folderID)
+	if repoFolder.IsErrFolderNotFound(err) {
+		log.Debug("folder not found", slog.String("folder_id", folderID))
+		response.SendError(ctx, http.StatusBadRequest, "folder not found")
+		return repoFolder.Folder{}, false
+	}
+	if err != nil {
+		log.Error("error occurred while getting folder",
+			slog.String("folder_id", folderID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get folder")
+		return repoFolder.Folder{}, false
+	}
+
+	if userID == "" || folder.UserID != userID {
+		log.Debug("not folder's owner",
+			slog.String("folder_id", folderID),
+			slog.String("user_id", userID),
+		)
+		response.SendError(ctx, http.StatusForbidden, "folder is not yours")
+		return repoFolder.Folder{}, false
+	}
+
+	return folder, true
+}
+
+// normalizeFolderName trims spaces of folder name and checks its length.
+func normalizeFolderName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	length := utf8.RuneCountInString(name)
+	return name, length > 0 && length <= MaxFolderNameLength
+}
+
+// toFolderResponse converts a folder from repository to response one.
+func toFolderResponse(folder repoFolder.Folder) response.Folder {
+	f := response.Folder{
+		ID:   folder.ID,
+		Name: folder.Name,
+		Urls: folder.Urls,
+	}
+	if folder.ParentID != nil {
+		f.ParentID = *folder.ParentID
+	}
+	return f
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/importer"
+	"backend/pkg/requestid"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// MaxImportSize is the maximum size of uploaded export file. Larger files are imported by makeshort-import command.
+const MaxImportSize = 128 << 20
+
+// MaxImportErrors is the maximum count of not imported records listed in response.
+const MaxImportErrors = 1000
+
+// ImportUrls    Imports URLs from export file of other shortener.
+// @Summary      Import URLs
+// @Description  Imports URLs from export file of Bitly (CSV), YOURLS (SQL dump or CSV) or Shlink (CSV), preserving aliases and clicks counters.
+// @Description  The file is uploaded as "file" form field or as a request body. Records with existing aliases are reported as conflicts.
+// @Security     AccessToken
+// @Tags         url
+// @Accept       multipart/form-data,text/csv,text/plain
+// @Produce      json
+// @Param        format query    string true  "Export format: bitly, yourls or shlink"
+// @Param        file   formData file   false "Export file"
+// @Success      200  {object}    response.UrlImport
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      413  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /url/import      [post]
+func (h *Handler) ImportUrls(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.ImportUrls"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	format := ctx.Query("format")
+	switch format {
+	case importer.FormatBitly, importer.FormatYourls, importer.FormatShlink:
+	default:
+		response.SendError(ctx, http.StatusBadRequest, "format must be bitly, yourls or shlink")
+		return
+	}
+
+	// import of large file may take longer than the server timeouts
+	rc := http.NewResponseController(ctx.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		log.Warn("can't reset read deadline", sl.Err(err))
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("can't reset write deadline", sl.Err(err))
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxImportSize)
+
+	var file io.Reader = ctx.Request.Body
+	if ctx.ContentType() == gin.MIMEMultipartPOSTForm {
+		fileHeader, err := ctx.FormFile("file")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.SendError(ctx, http.StatusRequestEntityTooLarge, "file is too large")
+			return
+		}
+		if err != nil {
+			log.Debug("error occurred while reading file", sl.Err(err))
+			response.SendError(ctx, http.StatusBadRequest, "file is missing")
+			return
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			log.Error("error occurred while opening file", sl.Err(err))
+			response.SendError(ctx, http.StatusInternalServerError, "can't read file")
+			return
+		}
+		defer f.Close()
+		file = f
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	errs := make([]response.ImportError, 0)
+	result, err := h.service.Importer.Import(ctx, userID, format, file, importer.Hooks{
+		OnError: func(e importer.RowError) {
+			if len(errs) < MaxImportErrors {
+				errs = append(errs, response.ImportError(e))
+			}
+		},
+		OnCreated: func(aliases []string) {
+			h.invalidateUrlCache(ctx, log, "", aliases...)
+		},
+	})
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		log.Debug("file is too large", slog.Int("imported", result.Imported))
+		response.SendError(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file is too large, %d urls imported before the limit", result.Imported))
+		return
+	case errors.Is(err, importer.ErrInvalidFile):
+		log.Debug("invalid file", slog.Int("imported", result.Imported), sl.Err(err))
+		response.SendError(ctx, http.StatusBadRequest,
+			fmt.Sprintf("%s, %d urls imported before the error", err.Error(), result.Imported))
+		return
+	case err != nil:
+		log.Error("error occurred while importing urls",
+			slog.String("format", format),
+			slog.Int("imported", result.Imported),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError,
+			fmt.Sprintf("can't import urls, %d urls imported before the error", result.Imported))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlImport{
+		Total:     result.Total,
+		Imported:  result.Imported,
+		Conflicts: result.Conflicts,
+		Invalid:   result.Invalid,
+		Errors:    errs,
+	})
+	log.Info("urls imported",
+		slog.String("format", format),
+		slog.String("user_id", userID),
+		slog.Int("total", result.Total),
+		slog.Int("imported", result.Imported),
+	)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/lib/passthrough"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// PreviewSuffix is a suffix of alias in preview path, e.g. /s/abc+.
+const PreviewSuffix = "+"
+
+// Preview       Shows where a short URL goes without redirect.
+// @Summary      Preview URL
+// @Description  Shows destination, creation date, owner and clicks of a short URL without redirect and without counting a click.
+// @Description  Destinations of redirect rules and variants are shown too, as visitors may be redirected to them instead.
+// @Description  Served on short URL with + suffix, or with preview=1 query if the URL doesn't pass query to destination. Destinations of password protected URLs are hidden.
+// @Tags         url
+// @Param        alias path string true "alias"
+// @Produce      json,html
+// @Success      200  {object}      response.UrlPreview
+// @Failure      404  {object}      response.Error
+// @Failure      410  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /{alias}+          [get]
+func (h *Handler) Preview(ctx *gin.Context, url repoUrl.URL) {
+	log := h.log.With(
+		slog.String("op", "handler.Preview"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	preview := response.UrlPreview{
+		Alias:     url.ShortURL,
+		Redirects: url.Redirects,
+		CreatedAt: url.CreatedAt,
+		ExpiresAt: url.ExpiresAt,
+		Protected: url.IsPasswordProtected(),
+	}
+	if !preview.Protected {
+		preview.Url = url.LongURL
+		if len(url.Rules) > 0 {
+			preview.Rules = toRuleResponses(url.Rules)
+		}
+		if len(url.Variants) > 0 {
+			preview.Variants = toVariantsResponse(url.Variants, url.VariantsSticky).Variants
+		}
+	}
+
+	host := requestHost(ctx, url)
+	preview.Domain = host
+
+	if url.UserID != nil {
+		owner, err := h.service.Repository.User.GetByID(ctx, *url.UserID)
+		if err != nil {
+			// preview is still useful without the owner
+			log.Error("error occurred while getting url owner",
+				slog.String("user_id", *url.UserID),
+				sl.Err(err),
+			)
+		}
+		preview.Owner = owner.Username
+	}
+
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		ctx.JSON(http.StatusOK, preview)
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "preview.html", gin.H{
+		"Preview":  preview,
+		"ShortUrl": h.shortUrl(host, url.ShortURL),
+	})
+}
+
+// isPreviewRequested reports whether preview=1 query is set. The query is accepted only by urls which don't pass
+// query to long url, otherwise the preview parameter may be meant for the destination.
+func isPreviewRequested(ctx *gin.Context, url repoUrl.URL) bool {
+	if policy := passthrough.Policy(url.QueryPassthrough); policy != "" && policy != passthrough.Off {
+		return false
+	}
+	preview, _ := strconv.ParseBool(ctx.Query("preview"))
+	return preview
+}
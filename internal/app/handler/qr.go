@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/domain"
+	"backend/internal/service/qr"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/pkg/requestid"
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+)
+
+// QRSuffix is a suffix of alias in public QR code path, e.g. /s/abc.qr.
+const QRSuffix = ".qr"
+
+// GetUrlQR      Renders QR code of the short URL.
+// @Summary      Get URL QR code
+// @Description  Renders QR code of the full short URL in PNG or SVG format
+// @Security     AccessToken
+// @Tags         url
+// @Param        id     path  string true  "id"
+// @Param        format query string false "Image format: png (default) or svg"
+// @Param        size   query int    false "Width and height in pixels, 64-2048, 256 by default"
+// @Param        margin query int    false "Quiet zone in modules, 0-16, 4 by default"
+// @Param        ecc    query string false "Error correction level: L, M (default), Q or H"
+// @Param        fg     query string false "Foreground hex color, 000000 by default"
+// @Param        bg     query string false "Background hex color, ffffff by default"
+// @Param        logo   query bool   false "Embed configured logo, PNG only"
+// @Produce      png,image/svg+xml
+// @Success      200  {file}        file
+// @Failure      400  {object}      response.Error
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/qr       [get]
+func (h *Handler) GetUrlQR(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUrlQR"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	opts, err := parseQrOptions(ctx)
+	if err != nil {
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "qr: "))
+		return
+	}
+
+	urlID := ctx.Param("id")
+
+	url, err := h.service.Repository.Url.GetByID(ctx, urlID)
+	if err != nil {
+		log.Error("error occurred while getting url",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get url")
+		return
+	}
+
+	var host string
+	if url.DomainID != nil {
+		d, err := h.service.Repository.Domain.GetByID(ctx, *url.DomainID)
+		if err != nil {
+			log.Error("error occurred while getting domain",
+				slog.String("id", *url.DomainID),
+				sl.Err(err),
+			)
+			response.SendError(ctx, http.StatusInternalServerError, "can't get url domain")
+			return
+		}
+		host = d.Host
+	}
+
+	h.sendQR(ctx, log, h.shortUrl(host, url.ShortURL), opts, "private, max-age=3600")
+}
+
+// AliasQR       Renders QR code of the short URL by its alias.
+// @Summary      Get alias QR code
+// @Description  Public variant of URL QR code, served on short URL with .qr suffix. Accepts the same options.
+// @Tags         url
+// @Param        alias  path  string true  "alias"
+// @Param        format query string false "Image format: png (default) or svg"
+// @Param        size   query int    false "Width and height in pixels, 64-2048, 256 by default"
+// @Param        margin query int    false "Quiet zone in modules, 0-16, 4 by default"
+// @Param        ecc    query string false "Error correction level: L, M (default), Q or H"
+// @Param        fg     query string false "Foreground hex color, 000000 by default"
+// @Param        bg     query string false "Background hex color, ffffff by default"
+// @Param        logo   query bool   false "Embed configured logo, PNG only"
+// @Produce      png,image/svg+xml
+// @Success      200  {file}        file
+// @Failure      400  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      410  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /{alias}.qr        [get]
+func (h *Handler) AliasQR(ctx *gin.Context, alias string) {
+	log := h.log.With(
+		slog.String("op", "handler.AliasQR"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	opts, err := parseQrOptions(ctx)
+	if err != nil {
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "qr: "))
+		return
+	}
+
+	url, ok := h.getRedirectUrl(ctx, log, alias)
+	if !ok {
+		return
+	}
+
+	h.sendQR(ctx, log, h.shortUrl(requestHost(ctx, url), url.ShortURL), opts, "public, max-age=3600")
+}
+
+// sendQR renders QR code of the content and sends it with given Cache-Control header.
+func (h *Handler) sendQR(ctx *gin.Context, log *slog.Logger, content string, opts qr.Options, cacheControl string) {
+	var buf bytes.Buffer
+	err := h.service.QR.Encode(&buf, content, opts)
+	if errors.Is(err, qr.ErrSizeTooSmall) || errors.Is(err, qr.ErrLogoNotFound) {
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "qr: "))
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while rendering qr code",
+			slog.String("content", content),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't render qr code")
+		return
+	}
+
+	contentType := "image/png"
+	if opts.Format == qr.FormatSVG {
+		contentType = "image/svg+xml"
+	}
+
+	ctx.Header("Cache-Control", cacheControl)
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// shortUrl returns the full short url of the alias on custom domain host, or on the default domain if host is empty.
+func (h *Handler) shortUrl(host string, alias string) string {
+	base, err := neturl.Parse(h.config.BaseURL)
+	if err != nil || base.Host == "" {
+		base = &neturl.URL{Scheme: "https", Host: host}
+	}
+	if host != "" {
+		base.Host = host
+	}
+	return base.Scheme + "://" + base.Host + "/s/" + neturl.PathEscape(alias)
+}
+
+// requestHost returns the normalized request host if the url belongs to a custom domain,
+// and an empty string for the default domain.
+func requestHost(ctx *gin.Context, url repoUrl.URL) string {
+	if url.DomainID == nil {
+		return ""
+	}
+	host, _ := domain.NormalizeHost(ctx.Request.Host)
+	return host
+}
+
+// parseQrOptions parses QR code options from query, using defaults for missing ones.
+func parseQrOptions(ctx *gin.Context) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	var err error
+
+	if format, ok := ctx.GetQuery("format"); ok {
+		opts.Format = strings.ToLower(format)
+	}
+	if size, ok := ctx.GetQuery("size"); ok {
+		if opts.Size, err = strconv.Atoi(size); err != nil {
+			return opts, qr.ErrInvalidSize
+		}
+	}
+	if margin, ok := ctx.GetQuery("margin"); ok {
+		if opts.Margin, err = strconv.Atoi(margin); err != nil {
+			return opts, qr.ErrInvalidMargin
+		}
+	}
+	if ecc, ok := ctx.GetQuery("ecc"); ok {
+		if opts.Level, err = qr.ParseLevel(ecc); err != nil {
+			return opts, err
+		}
+	}
+	if fg, ok := ctx.GetQuery("fg"); ok {
+		if opts.Foreground, err = qr.ParseColor(fg); err != nil {
+			return opts, err
+		}
+	}
+	if bg, ok := ctx.GetQuery("bg"); ok {
+		if opts.Background, err = qr.ParseColor(bg); err != nil {
+			return opts, err
+		}
+	}
+	if logo, ok := ctx.GetQuery("logo"); ok {
+		if opts.Logo, err = strconv.ParseBool(logo); err != nil {
+			return opts, errors.New("logo must be a boolean")
+		}
+	}
+
+	return opts, opts.Validate()
+}
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/lib/passthrough"
+	"backend/internal/lib/useragent"
+	"backend/internal/service/domain"
+	"backend/internal/service/repository"
+	"backend/internal/service/repository/postgres/click"
+	repoDomain "backend/internal/service/repository/postgres/domain"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/internal/service/repository/redis/urlcache"
+	"backend/internal/service/rules"
+	"backend/pkg/requestid"
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// Redirect redirects user from /{alias} to URL assigned to this alias.
+// Redirect      Redirects to an URL.
+// @Summary      Redirect to URL
+// @Description  Redirects to an URL with its redirect status code, 308 by default. Password protected URLs answer with a password prompt.
+// @Description  URL is replaced by URL of the first redirect rule matching country or User-Agent of visitor, or by URL of a weighted variant.
+// @Description  Path after alias, e.g. /{alias}/some/path, replaces {path} placeholder of URL. Query is passed to URL by its query_passthrough.
+// @Tags         url
+// @Param        alias path string true "alias"
+// @Success      308  {integer}     integer 1
+// @Failure      401  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      410  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /{alias}           [get]
+func (h *Handler) Redirect(ctx *gin.Context) {
+	alias := ctx.Param("alias")
+	if qrAlias, isQR := strings.CutSuffix(alias, QRSuffix); isQR {
+		h.AliasQR(ctx, qrAlias)
+		return
+	}
+	previewAlias, isPreview := strings.CutSuffix(alias, PreviewSuffix)
+	if isPreview {
+		alias = previewAlias
+	}
+
+	log := h.log.With(
+		slog.String("op", "handler.Redirect"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	url, ok := h.getRedirectUrl(ctx, log, alias)
+	if !ok {
+		return
+	}
+
+	if isPreview || isPreviewRequested(ctx, url) {
+		h.Preview(ctx, url)
+		return
+	}
+
+	if url.IsPasswordProtected() {
+		log.Debug("url is password protected",
+			slog.String("alias", url.ShortURL),
+		)
+		response.SendPasswordRequired(ctx, "this short link is protected by a password")
+		return
+	}
+
+	h.redirect(ctx, log, url, url.GetRedirectCode())
+}
+
+// Unlock        Redirects to a password protected URL.
+// @Summary      Unlock URL
+// @Description  Checks the password of a protected URL and redirects to it
+// @Tags         url
+// @Accept       json,x-www-form-urlencoded
+// @Param        alias path string true "alias"
+// @Param        input body         request.UrlUnlock true "Url password"
+// @Success      303  {integer}     integer 1
+// @Failure      400  {object}      response.Error
+// @Failure      401  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      410  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /{alias}           [post]
+func (h *Handler) Unlock(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.Unlock"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	url, ok := h.getRedirectUrl(ctx, log, ctx.Param("alias"))
+	if !ok {
+		return
+	}
+
+	if url.IsPasswordProtected() {
+		var body request.UrlUnlock
+
+		if err := ctx.ShouldBind(&body); err != nil {
+			log.Debug("error occurred while decode request body", sl.Err(err))
+			response.SendInvalidRequestBodyError(ctx)
+			return
+		}
+
+		if !h.checkUrlPassword(url, body.Password) {
+			log.Debug("wrong url password",
+				slog.String("alias", url.ShortURL),
+			)
+			response.SendPasswordRequired(ctx, "wrong password, try again")
+			return
+		}
+	}
+
+	h.redirect(ctx, log, url, http.StatusSeeOther)
+}
+
+// getRedirectUrl gets an url available for redirect by its alias.
+// If the url is not found or expired, the function sends an error response and returns false.
+func (h *Handler) getRedirectUrl(ctx *gin.Context, log *slog.Logger, alias string) (repoUrl.URL, bool) {
+	domainID, err := h.getRequestDomain(ctx, log)
+	if err != nil {
+		log.Error("error occurred while getting domain",
+			slog.String("host", ctx.Request.Host),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't found url")
+		return repoUrl.URL{}, false
+	}
+
+	url, err := h.getUrlByAlias(ctx, log, domainID, alias)
+	if errors.Is(err, repository.ErrURLNotFound) || repoUrl.IsErrUrlNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "url not found")
+		return repoUrl.URL{}, false
+	}
+	if err != nil {
+		log.Error("error occurred while getting url",
+			slog.String("alias", alias),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't found url")
+		return repoUrl.URL{}, false
+	}
+
+	if url.IsExpired(time.Now()) {
+		log.Debug("url is expired",
+			slog.String("alias", alias),
+		)
+		response.SendPageError(ctx, http.StatusGone, "this short link has expired and is no longer available")
+		return repoUrl.URL{}, false
+	}
+
+	return url, true
+}
+
+// getRequestDomain returns ID of verified custom domain, which the request was sent to,
+// or an empty string if the request was sent to the default domain.
+// Domains are got from cache, or from database on cache miss.
+func (h *Handler) getRequestDomain(ctx *gin.Context, log *slog.Logger) (string, error) {
+	host, err := domain.NormalizeHost(ctx.Request.Host)
+	if err != nil {
+		return "", nil // IP addresses and local hosts can't be custom domains
+	}
+
+	domainID, err := h.service.Repository.UrlCache.GetDomain(ctx, host)
+	if err == nil {
+		return domainID, nil
+	}
+	if !urlcache.IsErrCacheMiss(err) {
+		log.Error("error occurred while getting domain from cache",
+			slog.String("host", host),
+			sl.Err(err),
+		)
+	}
+
+	d, err := h.service.Repository.Domain.GetVerifiedByHost(ctx, host)
+	if err != nil && !repoDomain.IsErrDomainNotFound(err) {
+		return "", err
+	}
+	domainID = d.ID
+
+	if err = h.service.Repository.UrlCache.SetDomain(ctx, host, domainID); err != nil {
+		log.Error("error occurred while caching domain",
+			slog.String("host", host),
+			sl.Err(err),
+		)
+	}
+
+	return domainID, nil
+}
+
+// getUrlByAlias gets an url by its alias on the domain from cache, or from database on cache miss.
+// Urls from database are cached, and so are aliases which do not exist. Urls with redirects budget aren't cached,
+// so the budget is checked against the redirects counter in database rather than against a stale snapshot.
+// If cache is unavailable, the url is still got from database.
+func (h *Handler) getUrlByAlias(ctx *gin.Context, log *slog.Logger, domainID string, alias string) (repoUrl.URL, error) {
+	url, err := h.service.Repository.UrlCache.Get(ctx, domainID, alias)
+	if err == nil || repoUrl.IsErrUrlNotFound(err) {
+		return url, err
+	}
+	if !urlcache.IsErrCacheMiss(err) {
+		log.Error("error occurred while getting url from cache",
+			slog.String("alias", alias),
+			sl.Err(err),
+		)
+	}
+
+	url, err = h.service.Repository.Url.GetByShortUrl(ctx, domainID, alias)
+	if repoUrl.IsErrUrlNotFound(err) {
+		if cacheErr := h.service.Repository.UrlCache.SetNotFound(ctx, domainID, alias); cacheErr != nil {
+			log.Error("error occurred while caching not existing url",
+				slog.String("alias", alias),
+				sl.Err(cacheErr),
+			)
+		}
+		return repoUrl.URL{}, err
+	}
+	if err != nil {
+		return repoUrl.URL{}, err
+	}
+
+	if url.MaxRedirects != nil {
+		return url, nil
+	}
+
+	if err = h.service.Repository.UrlCache.Set(ctx, url); err != nil {
+		log.Error("error occurred while caching url",
+			slog.String("alias", alias),
+			sl.Err(err),
+		)
+	}
+
+	return url, nil
+}
+
+// redirect counts a redirect of the url and redirects user to its long url with given status code and headers of the url.
+// The long url is replaced by url of the first rule matching country or User-Agent of the user,
+// or by url of a variant picked by weights if no rule matches.
+// The path after alias and the query are passed to the long url by its passthrough options.
+// The click is saved and redirects counter is incremented asynchronously, so the redirect never waits for them.
+// Only redirects of urls with redirects budget are counted synchronously, spending the budget before the redirect.
+func (h *Handler) redirect(ctx *gin.Context, log *slog.Logger, url repoUrl.URL, statusCode int) {
+	visitor := rules.Visitor{
+		Agent:   useragent.Parse(ctx.Request.UserAgent()),
+		Country: h.service.GeoIP.Country(ctx.ClientIP()),
+	}
+
+	longUrl := url.LongURL
+	var variant string
+	if rule, ok := url.Rules.Match(visitor); ok {
+		longUrl = rule.Url
+	} else if v, ok := h.pickVariant(ctx, url); ok {
+		longUrl = v.Url
+		variant = v.ID
+	}
+
+	destination, err := passthrough.Expand(longUrl, ctx.Param("path"), ctx.Request.URL.Query(), passthrough.Policy(url.QueryPassthrough))
+	if err != nil {
+		log.Debug("can't pass request to url",
+			slog.String("alias", url.ShortURL),
+			slog.String("path", ctx.Param("path")),
+			sl.Err(err),
+		)
+		response.SendPageError(ctx, http.StatusNotFound, "url not found")
+		return
+	}
+
+	if url.MaxRedirects != nil {
+		err = h.service.Repository.Url.SpendRedirect(ctx, url.ID)
+		if repoUrl.IsErrRedirectsBudgetSpent(err) {
+			log.Debug("redirects budget is spent",
+				slog.String("alias", url.ShortURL),
+			)
+			response.SendPageError(ctx, http.StatusGone, "this short link has expired and is no longer available")
+			return
+		}
+		if err != nil {
+			log.Error("error occurred while spending redirect",
+				slog.String("alias", url.ShortURL),
+				sl.Err(err),
+			)
+			response.SendPageError(ctx, http.StatusInternalServerError, "can't redirect to url")
+			return
+		}
+	}
+
+	if url.CacheControl != "" {
+		ctx.Header("Cache-Control", url.CacheControl)
+	}
+	if url.ReferrerPolicy != "" {
+		ctx.Header("Referrer-Policy", url.ReferrerPolicy)
+	}
+	if url.RobotsTag != "" {
+		ctx.Header("X-Robots-Tag", url.RobotsTag)
+	}
+
+	if url.MaxRedirects == nil {
+		h.service.RedirectCounter.Add(h.newClick(ctx, url, visitor, variant))
+	} else {
+		h.service.RedirectCounter.AddClick(h.newClick(ctx, url, visitor, variant))
+	}
+
+	log.Debug("redirected",
+		slog.String("url", destination),
+		slog.String("alias", url.ShortURL),
+	)
+	ctx.Redirect(statusCode, destination)
+}
+
+// checkUrlPassword checks if given password matches the password of the url.
+func (h *Handler) checkUrlPassword(url repoUrl.URL, password string) bool {
+	if url.PasswordHash == nil {
+		return true
+	}
+
+	passwordHash := h.service.Hasher.Create(password)
+
+	return subtle.ConstantTimeCompare([]byte(passwordHash), []byte(*url.PasswordHash)) == 1
+}
+
+// newClick collects click event of the url from request, its visitor and the served variant.
+func (h *Handler) newClick(ctx *gin.Context, url repoUrl.URL, visitor rules.Visitor, variant string) click.Click {
+	referrer := ctx.Request.Referer()
+	userAgent := ctx.Request.UserAgent()
+
+	var referrerHost string
+	if parsedReferrer, err := neturl.Parse(referrer); err == nil {
+		referrerHost = parsedReferrer.Hostname()
+	}
+
+	return click.Click{
+		UrlID:        url.ID,
+		Referrer:     referrer,
+		ReferrerHost: referrerHost,
+		UserAgent:    userAgent,
+		Country:      visitor.Country,
+		Browser:      visitor.Agent.Browser,
+		OS:           visitor.Agent.OS,
+		Device:       visitor.Agent.Device,
+		Variant:      variant,
+		CreatedAt:    time.Now(),
+	}
+}
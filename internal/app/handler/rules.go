@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/lib/random"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/internal/service/rules"
+	"backend/pkg/requestid"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RuleIDLength is a length of generated rule IDs.
+const RuleIDLength = 8
+
+var errInvalidRuleUrl = errors.New("url of rule is invalid")
+
+// GetUrlRules   Returns redirect rules of an URL.
+// @Summary      Get URL rules
+// @Description  Returns ordered redirect rules of an URL. The first rule matching country or User-Agent of the visitor replaces the URL on redirect.
+// @Security     AccessToken
+// @Tags         url
+// @Param        id path string true "id"
+// @Produce      json
+// @Success      200  {array}       response.UrlRule
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/rules    [get]
+func (h *Handler) GetUrlRules(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUrlRules"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	url, ok := h.getUrl(ctx, log)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toRuleResponses(url.Rules))
+}
+
+// SetUrlRules   Replaces redirect rules of an URL.
+// @Summary      Set URL rules
+// @Description  Replaces all redirect rules of an URL, keeping their order. Values of one condition are alternatives,
+// @Description  different conditions must all match. Empty list removes the rules.
+// @Security     AccessToken
+// @Tags         url
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body []request.UrlRule true "Rules"
+// @Success      200  {array}       response.UrlRule
+// @Failure      400  {object}      response.Error
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/rules    [put]
+func (h *Handler) SetUrlRules(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.SetUrlRules"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body []request.UrlRule
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	if len(body) > rules.MaxRules {
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(rules.ErrTooManyRules.Error(), "rules: "))
+		return
+	}
+
+	list := make(rules.List, len(body))
+	for i, b := range body {
+		rule, err := newRule(b)
+		if err != nil {
+			log.Debug("provided rule is invalid", slog.Int("index", i), sl.Err(err))
+			response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "rules: "))
+			return
+		}
+		if !h.checkDestination(ctx, log, rule.Url, fmt.Sprintf("rule %d", i+1)) {
+			return
+		}
+		list[i] = rule
+	}
+
+	url, ok := h.getUrl(ctx, log)
+	if !ok {
+		return
+	}
+
+	if !h.setUrlRules(ctx, log, url, list) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toRuleResponses(list))
+	log.Info("url rules set",
+		slog.String("id", url.ID),
+		slog.Int("rules", len(list)),
+	)
+}
+
+// AddUrlRule    Appends a redirect rule to an URL.
+// @Summary      Add URL rule
+// @Description  Appends a redirect rule to the end of URL rules
+// @Security     AccessToken
+// @Tags         url
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body request.UrlRule true "Rule"
+// @Success      201  {object}      response.UrlRule
+// @Failure      400  {object}      response.Error
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/rules    [post]
+func (h *Handler) AddUrlRule(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.AddUrlRule"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.UrlRule
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	rule, err := newRule(body)
+	if err != nil {
+		log.Debug("provided rule is invalid", sl.Err(err))
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "rules: "))
+		return
+	}
+	if !h.checkDestination(ctx, log, rule.Url, "") {
+		return
+	}
+
+	url, ok := h.getUrl(ctx, log)
+	if !ok {
+		return
+	}
+
+	if len(url.Rules) >= rules.MaxRules {
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(rules.ErrTooManyRules.Error(), "rules: "))
+		return
+	}
+
+	if !h.setUrlRules(ctx, log, url, append(url.Rules, rule)) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toRuleResponse(rule))
+	log.Info("url rule added",
+		slog.String("id", url.ID),
+		slog.String("rule_id", rule.ID),
+	)
+}
+
+// DeleteUrlRule Deletes a redirect rule of an URL.
+// @Summary      Delete URL rule
+// @Description  Deletes a redirect rule of an URL, keeping order of other rules
+// @Security     AccessToken
+// @Tags         url
+// @Param        id      path string true "id"
+// @Param        rule_id path string true "rule id"
+// @Success      200
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/rules/{rule_id} [delete]
+func (h *Handler) DeleteUrlRule(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.DeleteUrlRule"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	url, ok := h.getUrl(ctx, log)
+	if !ok {
+		return
+	}
+
+	ruleID := ctx.Param("rule_id")
+
+	list, err := url.Rules.Remove(ruleID)
+	if errors.Is(err, rules.ErrRuleNotFound) {
+		response.SendError(ctx, http.StatusNotFound, "no rule to delete")
+		return
+	}
+
+	if !h.setUrlRules(ctx, log, url, list) {
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+	log.Info("url rule deleted",
+		slog.String("id", url.ID),
+		slog.String("rule_id", ruleID),
+	)
+}
+
+// getUrl gets an url by ID from path. If the url can't be got, the function sends an error response and returns false.
+func (h *Handler) getUrl(ctx *gin.Context, log *slog.Logger) (repoUrl.URL, bool) {
+	urlID := ctx.Param("id")
+
+	url, err := h.service.Repository.Url.GetByID(ctx, urlID)
+	if err != nil {
+		log.Error("error occurred while getting url",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get url")
+		return repoUrl.URL{}, false
+	}
+
+	return url, true
+}
+
+// setUrlRules saves rules of the url and invalidates its cache.
+// If rules can't be saved, the function sends an error response and returns false.
+func (h *Handler) setUrlRules(ctx *gin.Context, log *slog.Logger, url repoUrl.URL, list rules.List) bool {
+	_, err := h.service.Repository.Url.SetRules(ctx, url.ID, list)
+	if repoUrl.IsErrUrlNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "url with this id not found")
+		return false
+	}
+	if err != nil {
+		log.Error("error occurred while saving url rules",
+			slog.String("id", url.ID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't save url rules")
+		return false
+	}
+
+	h.invalidateUrlCache(ctx, log, url.GetDomainID(), url.ShortURL)
+
+	return true
+}
+
+// newRule validates a rule from request and returns it with a new ID.
+func newRule(body request.UrlRule) (rules.Rule, error) {
+	longUrl, ok := validateUrl(body.Url)
+	if !ok {
+		return rules.Rule{}, errInvalidRuleUrl
+	}
+
+	rule := rules.Rule{
+		ID:        random.Generate(RuleIDLength),
+		Countries: body.Countries,
+		OS:        body.OS,
+		Devices:   body.Devices,
+		Browsers:  body.Browsers,
+		Url:       longUrl,
+	}
+
+	return rule, rule.Normalize()
+}
+
+// toRuleResponses converts rules of an url to response ones.
+func toRuleResponses(list rules.List) []response.UrlRule {
+	rs := make([]response.UrlRule, len(list))
+	for i, rule := range list {
+		rs[i] = toRuleResponse(rule)
+	}
+	return rs
+}
+
+// toRuleResponse converts a rule of an url to response one.
+func toRuleResponse(rule rules.Rule) response.UrlRule {
+	return response.UrlRule{
+		ID:        rule.ID,
+		Countries: rule.Countries,
+		OS:        rule.OS,
+		Devices:   rule.Devices,
+		Browsers:  rule.Browsers,
+		Url:       rule.Url,
+	}
+}
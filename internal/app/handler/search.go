@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/pkg/requestid"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSearchQueryLength is the maximum length of search query in characters.
+	MaxSearchQueryLength = 200
+	// DefaultSearchLimit is the number of found urls returned, if limit isn't provided.
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the maximum number of found urls returned.
+	MaxSearchLimit = 100
+)
+
+// SearchUrls    Searches URLs of user.
+// @Summary      Search URLs
+// @Description  Searches URLs of user by words of their alias, destination, title and notes, ranked by relevance.
+// @Description  Query supports quoted phrases, "or" and "-" to exclude words. Aliases are also matched by any part.
+// @Security     AccessToken
+// @Tags         url
+// @Param        q     query string true  "search query"
+// @Param        limit query int    false "number of URLs, 20 by default, up to 100"
+// @Produce      json
+// @Success      200  {array}       response.URL
+// @Failure      400  {object}      response.Error
+// @Failure      401  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/search        [get]
+func (h *Handler) SearchUrls(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.SearchUrls"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" || utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("q must be 1-%d characters", MaxSearchQueryLength))
+		return
+	}
+
+	limit := DefaultSearchLimit
+	if value := ctx.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxSearchLimit {
+			response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", MaxSearchLimit))
+			return
+		}
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	urlDocs, err := h.service.Repository.User.SearchUrls(ctx, userID, query, limit)
+	if err != nil {
+		log.Error("error occurred while searching user urls",
+			slog.String("id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't search urls")
+		return
+	}
+
+	urls := make([]response.URL, len(urlDocs))
+	for i, url := range urlDocs {
+		urls[i] = toUrlResponse(url)
+	}
+
+	ctx.JSON(http.StatusOK, urls)
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/lib/timeseries"
+	"backend/internal/service/repository/postgres/click"
+	"backend/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// MaxTimeSeriesBuckets is the maximum number of buckets in one time series response.
+const MaxTimeSeriesBuckets = 1000
+
+// defaultTimeSeriesRange is a time range of time series with interval, when `from` is not provided.
+var defaultTimeSeriesRange = map[timeseries.Interval]time.Duration{
+	timeseries.Hour: 24 * time.Hour,
+	timeseries.Day:  30 * 24 * time.Hour,
+	timeseries.Week: 12 * 7 * 24 * time.Hour,
+}
+
+type timeSeriesQuery struct {
+	interval timeseries.Interval
+	location *time.Location
+	buckets  []time.Time
+}
+
+// GetUrlStats   Gets clicks statistics of an URL.
+// @Summary      Get URL stats
+// @Description  Get total clicks of an URL and their breakdowns by referrer, country, device, browser and variant
+// @Security     AccessToken
+// @Tags         stats
+// @Param        id path string true "id"
+// @Produce      json
+// @Success      200  {object}      response.UrlStats
+// @Failure      401  {object}      response.Error
+// @Failure      403  {object}      response.Error
+// @Failure      404  {object}      response.Error
+// @Failure      500  {object}      response.Error
+// @Router       /url/{id}/stats    [get]
+func (h *Handler) GetUrlStats(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUrlStats"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	urlID := ctx.Param("id")
+
+	url, err := h.service.Repository.Url.GetByID(ctx, urlID)
+	if err != nil {
+		log.Error("error occurred while getting url",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get url")
+		return
+	}
+
+	stats, err := h.service.Repository.Click.GetStats(ctx, urlID)
+	if err != nil {
+		log.Error("error occurred while getting url stats",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get url stats")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlStats{
+		ID:        url.ID,
+		Alias:     url.ShortURL,
+		Total:     stats.Total,
+		Referrers: toStatsEntries(stats.Referrers),
+		Countries: toStatsEntries(stats.Countries),
+		Devices:   toStatsEntries(stats.Devices),
+		Browsers:  toStatsEntries(stats.Browsers),
+		Variants:  toStatsEntries(stats.Variants),
+	})
+}
+
+// toStatsEntries converts repository stats entries to response ones.
+func toStatsEntries(entries []click.Entry) []response.StatsEntry {
+	result := make([]response.StatsEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = response.StatsEntry{
+			Value: entry.Value,
+			Count: entry.Count,
+		}
+	}
+	return result
+}
+
+// GetUrlTimeSeries  Gets clicks count of an URL over time.
+// @Summary          Get URL time series
+// @Description      Get clicks count of an URL per hour, day or week. Buckets without clicks are included with zero count.
+// @Security         AccessToken
+// @Tags             stats
+// @Param            id path string true "id"
+// @Param            from query string false "Start of range, RFC 3339 or YYYY-MM-DD"
+// @Param            to query string false "End of range, RFC 3339 or YYYY-MM-DD, now by default"
+// @Param            interval query string false "Bucket size" Enums(hour, day, week)
+// @Param            tz query string false "IANA time zone of buckets, UTC by default"
+// @Produce          json
+// @Success          200  {object}                 response.TimeSeries
+// @Failure          400  {object}                 response.Error
+// @Failure          401  {object}                 response.Error
+// @Failure          403  {object}                 response.Error
+// @Failure          404  {object}                 response.Error
+// @Failure          500  {object}                 response.Error
+// @Router           /url/{id}/stats/timeseries    [get]
+func (h *Handler) GetUrlTimeSeries(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUrlTimeSeries"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	urlID := ctx.Param("id")
+
+	query, message, ok := parseTimeSeriesQuery(ctx)
+	if !ok {
+		log.Debug("invalid time series query", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	buckets, err := h.service.Repository.Click.GetTimeSeries(ctx, urlID, string(query.interval), query.location.String(), query.from(), query.to())
+	if err != nil {
+		log.Error("error occurred while getting url time series",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get url time series")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, query.toResponse(buckets))
+}
+
+// GetUserUrlsTimeSeries  Gets clicks count of all user's URLs over time.
+// @Summary               Get URLs time series
+// @Description           Get clicks count of all URLs created by user per hour, day or week. Buckets without clicks are included with zero count.
+// @Security              AccessToken
+// @Tags                  stats
+// @Param                 id path string true "id"
+// @Param                 from query string false "Start of range, RFC 3339 or YYYY-MM-DD"
+// @Param                 to query string false "End of range, RFC 3339 or YYYY-MM-DD, now by default"
+// @Param                 interval query string false "Bucket size" Enums(hour, day, week)
+// @Param                 tz query string false "IANA time zone of buckets, UTC by default"
+// @Produce               json
+// @Success               200  {object}                       response.TimeSeries
+// @Failure               400  {object}                       response.Error
+// @Failure               401  {object}                       response.Error
+// @Failure               500  {object}                       response.Error
+// @Router                /user/{id}/urls/stats/timeseries    [get]
+func (h *Handler) GetUserUrlsTimeSeries(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUserUrlsTimeSeries"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	query, message, ok := parseTimeSeriesQuery(ctx)
+	if !ok {
+		log.Debug("invalid time series query", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	buckets, err := h.service.Repository.Click.GetUserTimeSeries(ctx, userID, string(query.interval), query.location.String(), query.from(), query.to())
+	if err != nil {
+		log.Error("error occurred while getting user urls time series",
+			slog.String("id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get urls time series")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, query.toResponse(buckets))
+}
+
+// parseTimeSeriesQuery parses and validates time series query parameters.
+// If the parameters are invalid, the function returns the reason and false.
+func parseTimeSeriesQuery(ctx *gin.Context) (timeSeriesQuery, string, bool) {
+	interval, err := timeseries.ParseInterval(ctx.Query("interval"))
+	if err != nil {
+		return timeSeriesQuery{}, err.Error(), false
+	}
+
+	tz := ctx.DefaultQuery("tz", "UTC")
+	location, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return timeSeriesQuery{}, "tz must be a valid IANA time zone", false
+	}
+
+	to := time.Now()
+	if rawTo := ctx.Query("to"); rawTo != "" {
+		to, err = parseTimeParam(rawTo, location)
+		if err != nil {
+			return timeSeriesQuery{}, "to must be in RFC 3339 or YYYY-MM-DD format", false
+		}
+	}
+
+	from := to.Add(-defaultTimeSeriesRange[interval])
+	if rawFrom := ctx.Query("from"); rawFrom != "" {
+		from, err = parseTimeParam(rawFrom, location)
+		if err != nil {
+			return timeSeriesQuery{}, "from must be in RFC 3339 or YYYY-MM-DD format", false
+		}
+	}
+
+	if from.After(to) {
+		return timeSeriesQuery{}, "from must not be after to", false
+	}
+
+	buckets, ok := timeseries.Buckets(from, to, interval, location, MaxTimeSeriesBuckets)
+	if !ok {
+		return timeSeriesQuery{}, "too many buckets, use a shorter range or a bigger interval", false
+	}
+
+	return timeSeriesQuery{
+		interval: interval,
+		location: location,
+		buckets:  buckets,
+	}, "", true
+}
+
+// parseTimeParam parses time in RFC 3339 format, or date in YYYY-MM-DD format as a midnight in location loc.
+func parseTimeParam(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// from returns the start of the first bucket.
+func (q timeSeriesQuery) from() time.Time {
+	return q.buckets[0]
+}
+
+// to returns the end of the last bucket.
+func (q timeSeriesQuery) to() time.Time {
+	return q.interval.Next(q.buckets[len(q.buckets)-1])
+}
+
+// toResponse converts clicks buckets from database to a time series response with every bucket filled.
+func (q timeSeriesQuery) toResponse(buckets []click.Bucket) response.TimeSeries {
+	points := make([]timeseries.Point, len(buckets))
+	for i, bucket := range buckets {
+		points[i] = timeseries.Point{
+			Time:  timeseries.WallClock(bucket.Start, q.location),
+			Value: bucket.Count,
+		}
+	}
+
+	filled := timeseries.Fill(q.buckets, points)
+
+	series := response.TimeSeries{
+		Interval: string(q.interval),
+		TimeZone: q.location.String(),
+		From:     q.from(),
+		To:       q.to(),
+		Points:   make([]response.TimeSeriesPoint, len(filled)),
+	}
+	for i, point := range filled {
+		series.Total += point.Value
+		series.Points[i] = response.TimeSeriesPoint{
+			Time:   point.Time,
+			Clicks: point.Value,
+		}
+	}
+
+	return series
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"backend/internal/app/middleware"
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/repository"
+	repoTag "backend/internal/service/repository/postgres/tag"
+	"backend/pkg/requestid"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTagNameLength is the maximum length of tag name in characters.
+	MaxTagNameLength = 50
+	// MaxBulkUrls is the maximum count of urls changed by one bulk request.
+	MaxBulkUrls = 1000
+)
+
+// CreateTag     Creates a tag of user.
+// @Summary      Create tag
+// @Description  Creates a tag of user. Tag names are unique per user.
+// @Security     AccessToken
+// @Tags         tag
+// @Accept       json
+// @Produce      json
+// @Param        input body       request.TagCreate true "Tag data"
+// @Success      201  {object}    response.Tag
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      409  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag             [post]
+func (h *Handler) CreateTag(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.CreateTag"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.TagCreate
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	name, ok := normalizeTagName(body.Name)
+	if !ok {
+		response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("name must be 1-%d characters", MaxTagNameLength))
+		return
+	}
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	tag, err := h.service.Repository.Tag.Create(ctx, userID, name)
+	if errors.Is(err, repository.ErrTagAlreadyExists) {
+		response.SendConflictError(ctx, "tag already exists", repository.ConflictField(err))
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while creating tag",
+			slog.String("name", name),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't create tag")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toTagResponse(tag))
+	log.Info("tag created",
+		slog.String("id", tag.ID),
+		slog.String("name", tag.Name),
+	)
+}
+
+// GetTags       Returns tags of user.
+// @Summary      Get tags
+// @Description  Returns all tags of user with counts of their urls, sorted by name
+// @Security     AccessToken
+// @Tags         tag
+// @Produce      json
+// @Success      200  {array}     response.Tag
+// @Failure      401  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag             [get]
+func (h *Handler) GetTags(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetTags"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	tags, err := h.service.Repository.Tag.GetListByUser(ctx, userID)
+	if err != nil {
+		log.Error("error occurred while getting tags",
+			slog.String("user_id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get tags")
+		return
+	}
+
+	result := make([]response.Tag, len(tags))
+	for i, tag := range tags {
+		result[i] = toTagResponse(tag)
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// UpdateTag     Renames a tag.
+// @Summary      Update tag
+// @Description  Renames a tag, tagged urls keep it
+// @Security     AccessToken
+// @Tags         tag
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body       request.TagUpdate true "Tag data"
+// @Success      200  {object}    response.Tag
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      409  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag/{id}        [patch]
+func (h *Handler) UpdateTag(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.UpdateTag"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.TagUpdate
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	name, ok := normalizeTagName(body.Name)
+	if !ok {
+		response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("name must be 1-%d characters", MaxTagNameLength))
+		return
+	}
+
+	tagID := ctx.Param("id")
+
+	tag, err := h.service.Repository.Tag.Rename(ctx, tagID, name)
+	if repoTag.IsErrTagNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "tag with this id not found")
+		return
+	}
+	if errors.Is(err, repository.ErrTagAlreadyExists) {
+		response.SendConflictError(ctx, "tag already exists", repository.ConflictField(err))
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while renaming tag",
+			slog.String("id", tagID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't update tag")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTagResponse(tag))
+}
+
+// DeleteTag     Deletes a tag.
+// @Summary      Delete tag
+// @Description  Deletes a tag, urls lose it
+// @Security     AccessToken
+// @Tags         tag
+// @Param        id path string true "id"
+// @Success      200
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag/{id}        [delete]
+func (h *Handler) DeleteTag(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.DeleteTag"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	tagID := ctx.Param("id")
+
+	err := h.service.Repository.Tag.Delete(ctx, tagID)
+	if repoTag.IsErrTagNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "no tag to delete")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while deleting tag",
+			slog.String("id", tagID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't delete tag")
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+	log.Info("tag deleted",
+		slog.String("id", tagID),
+	)
+}
+
+// TagUrls       Tags many URLs at once.
+// @Summary      Tag URLs
+// @Description  Adds a tag to many URLs of user at once. URLs of other users and already tagged URLs are skipped.
+// @Security     AccessToken
+// @Tags         tag
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body       request.UrlIDs true "IDs of URLs, up to 1000"
+// @Success      200  {object}    response.UrlsAffected
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag/{id}/urls   [post]
+func (h *Handler) TagUrls(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.TagUrls"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	ids, ok := bindUrlIDs(ctx, log)
+	if !ok {
+		return
+	}
+
+	tagID := ctx.Param("id")
+	userID := ctx.GetString(middleware.ContextUserID)
+
+	affected, err := h.service.Repository.Tag.AddUrls(ctx, tagID, userID, ids)
+	if err != nil {
+		log.Error("error occurred while tagging urls",
+			slog.String("id", tagID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't tag urls")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlsAffected{Affected: affected})
+	log.Info("urls tagged",
+		slog.String("id", tagID),
+		slog.Int64("affected", affected),
+	)
+}
+
+// UntagUrls     Removes a tag from many URLs at once.
+// @Summary      Untag URLs
+// @Description  Removes a tag from many URLs at once. URLs without the tag are skipped.
+// @Security     AccessToken
+// @Tags         tag
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "id"
+// @Param        input body       request.UrlIDs true "IDs of URLs, up to 1000"
+// @Success      200  {object}    response.UrlsAffected
+// @Failure      400  {object}    response.Error
+// @Failure      401  {object}    response.Error
+// @Failure      403  {object}    response.Error
+// @Failure      404  {object}    response.Error
+// @Failure      500  {object}    response.Error
+// @Router       /tag/{id}/urls   [delete]
+func (h *Handler) UntagUrls(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.UntagUrls"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	ids, ok := bindUrlIDs(ctx, log)
+	if !ok {
+		return
+	}
+
+	tagID := ctx.Param("id")
+
+	affected, err := h.service.Repository.Tag.RemoveUrls(ctx, tagID, ids)
+	if err != nil {
+		log.Error("error occurred while untagging urls",
+			slog.String("id", tagID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't untag urls")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.UrlsAffected{Affected: affected})
+	log.Info("urls untagged",
+		slog.String("id", tagID),
+		slog.Int64("affected", affected),
+	)
+}
+
+// bindUrlIDs binds IDs of urls for a bulk request from body.
+// If IDs are invalid, the function sends an error response and returns false.
+func bindUrlIDs(ctx *gin.Context, log *slog.Logger) ([]string, bool) {
+	var body request.UrlIDs
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return nil, false
+	}
+
+	if len(body.IDs) == 0 || len(body.IDs) > MaxBulkUrls {
+		response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("ids must contain 1-%d url ids", MaxBulkUrls))
+		return nil, false
+	}
+	for _, id := range body.IDs {
+		if _, err := uuid.Parse(id); err != nil {
+			response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("url id %q is invalid", id))
+			return nil, false
+		}
+	}
+
+	return body.IDs, true
+}
+
+// normalizeTagName trims spaces of tag name and checks its length.
+func normalizeTagName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	length := utf8.RuneCountInString(name)
+	return name, length > 0 && length <= MaxTagNameLength
+}
+
+// toTagResponse converts a tag from repository to response one.
+func toTagResponse(tag repoTag.Tag) response.Tag {
+	return response.Tag{
+		ID:   tag.ID,
+		Name: tag.Name,
+		Urls: tag.Urls,
+	}
+}
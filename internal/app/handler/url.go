@@ -5,18 +5,31 @@ import (
 	"backend/internal/app/request"
 	"backend/internal/app/response"
 	"backend/internal/lib/logger/sl"
-	"backend/internal/lib/random"
+	"backend/internal/lib/passthrough"
+	serviceAlias "backend/internal/service/alias"
+	"backend/internal/service/destination"
 	"backend/internal/service/repository"
 	repoUrl "backend/internal/service/repository/postgres/url"
 	"backend/pkg/requestid"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
 	neturl "net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
-const AliasLength = 6
+const (
+	// MaxUrlLength is the maximum length of long url in bytes, which can be stored in database.
+	MaxUrlLength = repoUrl.MaxLongURLLength
+	// MaxTitleLength is the maximum length of url title in characters.
+	MaxTitleLength = 255
+	// MaxNotesLength is the maximum length of url notes in characters.
+	MaxNotesLength = 2000
+)
 
 // CreateUrl     Creates a URL in database, assigned to user.
 // @Summary      Create URL
@@ -31,6 +44,7 @@ const AliasLength = 6
 // @Failure      401  {object}    response.Error
 // @Failure      409  {object}    response.Error
 // @Failure      500  {object}    response.Error
+// @Failure      503  {object}    response.Error
 // @Router       /url             [post]
 func (h *Handler) CreateUrl(ctx *gin.Context) {
 	log := h.log.With(
@@ -55,19 +69,116 @@ func (h *Handler) CreateUrl(ctx *gin.Context) {
 		return
 	}
 
-	alias := body.Alias
-	if alias == "" {
-		alias = random.Generate(AliasLength)
+	if len(parsedUrl) > MaxUrlLength {
+		response.SendError(ctx, http.StatusBadRequest, "url is too long")
+		return
+	}
+
+	if parsedUrl != "" && !h.checkDestination(ctx, log, parsedUrl, "") {
+		return
+	}
+
+	if message, ok := validateExpiration(body.ExpiresAt, body.MaxRedirects); !ok {
+		log.Debug("provided expiration is invalid", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	if message, ok := validateRedirectOptions(body.RedirectCode, &body.CacheControl, &body.ReferrerPolicy, &body.RobotsTag); !ok {
+		log.Debug("provided redirect options are invalid", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	if message, ok := validateDescription(&body.Title, &body.Notes); !ok {
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	queryPassthrough, err := passthrough.ParsePolicy(body.QueryPassthrough)
+	if err != nil {
+		response.SendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if body.Alias != "" {
+		alias, err := h.service.AliasPolicy.Normalize(body.Alias)
+		if err != nil {
+			log.Debug("provided alias violates policy", slog.String("alias", body.Alias), sl.Err(err))
+			response.SendError(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		body.Alias = alias
 	}
 
 	userID := ctx.GetString(middleware.ContextUserID)
 
-	urlID, err := h.service.Repository.Url.Create(ctx, userID, parsedUrl, alias)
+	var domainID string
+	if body.Domain != "" {
+		d, ok := h.getUserDomain(ctx, log, userID, body.Domain)
+		if !ok {
+			return
+		}
+		domainID = d.ID
+	}
+
+	if body.FolderID != "" {
+		if _, ok := h.getUserFolder(ctx, log, userID, body.FolderID); !ok {
+			return
+		}
+	}
+
+	var passwordHash *string
+	if body.Password != "" {
+		hash := h.service.Hasher.Create(body.Password)
+		passwordHash = &hash
+	}
+
+	dto := repoUrl.DTO{
+		LongURL:          parsedUrl,
+		ExpiresAt:        body.ExpiresAt,
+		MaxRedirects:     body.MaxRedirects,
+		PasswordHash:     passwordHash,
+		DomainID:         domainID,
+		RedirectCode:     body.RedirectCode,
+		CacheControl:     &body.CacheControl,
+		ReferrerPolicy:   &body.ReferrerPolicy,
+		RobotsTag:        &body.RobotsTag,
+		QueryPassthrough: (*string)(&queryPassthrough),
+		FolderID:         &body.FolderID,
+		Title:            &body.Title,
+		Notes:            &body.Notes,
+	}
+
+	var urlID string
+	create := func(alias string) error {
+		dto.ShortURL = alias
+		urlID, err = h.service.Repository.Url.Create(ctx, userID, dto)
+		return err
+	}
+
+	alias := body.Alias
+	if alias == "" {
+		alias, err = h.service.Alias.Allocate(ctx, create)
+	} else {
+		err = create(alias)
+	}
+	if errors.Is(err, serviceAlias.ErrNoAliasAvailable) {
+		log.Error("no unique alias is available, increase alias length in config",
+			slog.String("url", parsedUrl),
+		)
+		response.SendError(ctx, http.StatusServiceUnavailable, "can't generate alias, try again later")
+		return
+	}
 	if errors.Is(err, repository.ErrAliasAlreadyExists) {
 		log.Debug("alias already exists",
 			slog.String("alias", alias),
 		)
-		response.SendError(ctx, http.StatusConflict, "alias already exists")
+		response.SendConflictError(ctx, "alias already exists", repository.ConflictField(err))
+		return
+	}
+	if repoUrl.IsErrFolderNotFound(err) {
+		response.SendError(ctx, http.StatusBadRequest, "folder not found")
 		return
 	}
 	if err != nil {
@@ -80,10 +191,29 @@ func (h *Handler) CreateUrl(ctx *gin.Context) {
 		return
 	}
 
+	h.invalidateUrlCache(ctx, log, domainID, alias)
+
+	redirectCode := repoUrl.DefaultRedirectCode
+	if body.RedirectCode != nil {
+		redirectCode = *body.RedirectCode
+	}
+
 	ctx.JSON(http.StatusCreated, response.UrlCreated{
-		ID:    urlID,
-		Url:   body.Url,
-		Alias: alias,
+		ID:               urlID,
+		Url:              body.Url,
+		Alias:            alias,
+		ExpiresAt:        body.ExpiresAt,
+		MaxRedirects:     body.MaxRedirects,
+		Protected:        passwordHash != nil,
+		Domain:           body.Domain,
+		RedirectCode:     redirectCode,
+		CacheControl:     body.CacheControl,
+		ReferrerPolicy:   body.ReferrerPolicy,
+		RobotsTag:        body.RobotsTag,
+		QueryPassthrough: string(queryPassthrough),
+		FolderID:         body.FolderID,
+		Title:            body.Title,
+		Notes:            body.Notes,
 	})
 	log.Info("url saved",
 		slog.String("id", urlID),
@@ -99,11 +229,13 @@ func (h *Handler) CreateUrl(ctx *gin.Context) {
 // @Tags         url
 // @Param        id path string true "id"
 // @Produce      json
-// @Param        input body       request.URL true "Url data"
-// @Success      200  {integer}     integer 1
+// @Param        input body       request.UrlUpdate true "Url data"
+// @Success      200  {object}      response.UrlUpdated
+// @Failure      400  {object}      response.Error
 // @Failure      401  {object}      response.Error
 // @Failure      403  {object}      response.Error
 // @Failure      404  {object}      response.Error
+// @Failure      409  {object}      response.Error
 // @Failure      500  {object}      response.Error
 // @Router       /url/{id}          [patch]
 func (h *Handler) UpdateUrl(ctx *gin.Context) {
@@ -131,10 +263,106 @@ func (h *Handler) UpdateUrl(ctx *gin.Context) {
 		return
 	}
 
+	if len(parsedUrl) > MaxUrlLength {
+		response.SendError(ctx, http.StatusBadRequest, "url is too long")
+		return
+	}
+
+	if parsedUrl != "" && !h.checkDestination(ctx, log, parsedUrl, "") {
+		return
+	}
+
+	if message, ok := validateExpiration(body.ExpiresAt, body.MaxRedirects); !ok {
+		log.Debug("provided expiration is invalid", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+	if (body.ClearExpiresAt && body.ExpiresAt != nil) || (body.ClearMaxRedirects && body.MaxRedirects != nil) {
+		response.SendError(ctx, http.StatusBadRequest, "expiration can't be set and cleared at once")
+		return
+	}
+
+	if message, ok := validateRedirectOptions(body.RedirectCode, body.CacheControl, body.ReferrerPolicy, body.RobotsTag); !ok {
+		log.Debug("provided redirect options are invalid", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	if message, ok := validateDescription(body.Title, body.Notes); !ok {
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
+	if body.QueryPassthrough != nil {
+		if _, err := passthrough.ParsePolicy(*body.QueryPassthrough); err != nil {
+			response.SendError(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if body.Alias != "" {
+		alias, err := h.service.AliasPolicy.Normalize(body.Alias)
+		if err != nil {
+			log.Debug("provided alias violates policy", slog.String("alias", body.Alias), sl.Err(err))
+			response.SendError(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		body.Alias = alias
+	}
+
+	oldUrl, err := h.service.Repository.Url.GetByID(ctx, urlID)
+	if err != nil {
+		log.Error("error occurred while getting url",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't update url")
+		return
+	}
+
+	if body.FolderID != nil && *body.FolderID != "" {
+		if _, ok := h.getUserFolder(ctx, log, ctx.GetString(middleware.ContextUserID), *body.FolderID); !ok {
+			return
+		}
+	}
+
+	var passwordHash *string
+	if body.Password != nil {
+		hash := ""
+		if *body.Password != "" {
+			hash = h.service.Hasher.Create(*body.Password)
+		}
+		passwordHash = &hash
+	}
+
 	url, err := h.service.Repository.Url.Update(ctx, urlID, repoUrl.DTO{
-		LongURL:  body.Alias,
-		ShortURL: parsedUrl,
+		LongURL:           parsedUrl,
+		ShortURL:          body.Alias,
+		ExpiresAt:         body.ExpiresAt,
+		MaxRedirects:      body.MaxRedirects,
+		ClearExpiresAt:    body.ClearExpiresAt,
+		ClearMaxRedirects: body.ClearMaxRedirects,
+		PasswordHash:      passwordHash,
+		RedirectCode:      body.RedirectCode,
+		CacheControl:      body.CacheControl,
+		ReferrerPolicy:    body.ReferrerPolicy,
+		RobotsTag:         body.RobotsTag,
+		QueryPassthrough:  body.QueryPassthrough,
+		FolderID:          body.FolderID,
+		Title:             body.Title,
+		Notes:             body.Notes,
 	})
+	if errors.Is(err, repository.ErrAliasAlreadyExists) {
+		log.Debug("alias already exists",
+			slog.String("alias", body.Alias),
+		)
+		response.SendConflictError(ctx, "alias already exists", repository.ConflictField(err))
+		return
+	}
+	if repoUrl.IsErrFolderNotFound(err) {
+		response.SendError(ctx, http.StatusBadRequest, "folder not found")
+		return
+	}
 	if err != nil {
 		log.Error("error occurred while updating url",
 			slog.String("id", urlID),
@@ -144,10 +372,28 @@ func (h *Handler) UpdateUrl(ctx *gin.Context) {
 		return
 	}
 
+	h.invalidateUrlCache(ctx, log, oldUrl.GetDomainID(), oldUrl.ShortURL, url.ShortURL)
+
+	var folderID string
+	if url.FolderID != nil {
+		folderID = *url.FolderID
+	}
+
 	ctx.JSON(http.StatusOK, response.UrlUpdated{
-		ID:    urlID,
-		Url:   url.LongURL,
-		Alias: url.ShortURL,
+		ID:               urlID,
+		Url:              url.LongURL,
+		Alias:            url.ShortURL,
+		ExpiresAt:        url.ExpiresAt,
+		MaxRedirects:     url.MaxRedirects,
+		Protected:        url.IsPasswordProtected(),
+		RedirectCode:     url.GetRedirectCode(),
+		CacheControl:     url.CacheControl,
+		ReferrerPolicy:   url.ReferrerPolicy,
+		RobotsTag:        url.RobotsTag,
+		QueryPassthrough: url.QueryPassthrough,
+		FolderID:         folderID,
+		Title:            url.Title,
+		Notes:            url.Notes,
 	})
 }
 
@@ -220,6 +466,8 @@ func (h *Handler) DeleteUrl(ctx *gin.Context) {
 		return
 	}
 
+	h.invalidateUrlCache(ctx, log, url.GetDomainID(), url.ShortURL)
+
 	ctx.Status(http.StatusOK)
 	log.Info("url deleted successfully",
 		slog.String("id", urlID),
@@ -227,58 +475,137 @@ func (h *Handler) DeleteUrl(ctx *gin.Context) {
 	)
 }
 
-// Redirect redirects user from /{alias} to URL assigned to this alias.
-// Redirect      Redirects to an URL.
-// @Summary      Redirect to URL
-// @Description  Redirects to an URL
-// @Tags         url
-// @Param        alias path string true "alias"
-// @Success      308  {integer}     integer 1
-// @Failure      404  {object}      response.Error
-// @Failure      500  {object}      response.Error
-// @Router       /{alias}           [get]
-func (h *Handler) Redirect(ctx *gin.Context) {
-	log := h.log.With(
-		slog.String("op", "handler.Redirect"),
-		slog.String("request_id", requestid.Get(ctx)),
-	)
-
-	alias := ctx.Param("alias")
-
-	url, err := h.service.Repository.Url.GetByShortUrl(ctx, alias)
-	if errors.Is(err, repository.ErrURLNotFound) {
-		response.SendError(ctx, http.StatusNotFound, "url not found")
-		return
-	}
+// invalidateUrlCache deletes cached urls by their aliases, so changes of urls are visible on redirect immediately.
+func (h *Handler) invalidateUrlCache(ctx *gin.Context, log *slog.Logger, domainID string, aliases ...string) {
+	err := h.service.Repository.UrlCache.Delete(ctx, domainID, aliases...)
 	if err != nil {
-		log.Error("error occurred while getting url",
-			slog.String("alias", alias),
+		log.Error("error occurred while invalidating url cache",
+			slog.String("domain_id", domainID),
+			slog.Any("aliases", aliases),
 			sl.Err(err),
 		)
-		response.SendError(ctx, http.StatusInternalServerError, "can't found url")
-		return
 	}
+}
+
+// pathMarker temporarily replaces path placeholder of url while it's parsed, because parser escapes braces.
+const pathMarker = "makeshort-path-placeholder"
 
-	err = h.service.Repository.Url.IncrementRedirectsCounter(ctx, url.ID)
+// checkDestination checks the url against the destination policy. If the url can't be a destination,
+// the function sends an error response, prefixed by subject if it isn't empty, and returns false.
+func (h *Handler) checkDestination(ctx *gin.Context, log *slog.Logger, longUrl string, subject string) bool {
+	err := h.service.Destination.Check(ctx, longUrl)
+	if destination.IsViolation(err) {
+		log.Info("url violates destination policy",
+			slog.String("url", longUrl),
+			sl.Err(err),
+		)
+		message := err.Error()
+		if subject != "" {
+			message = subject + ": " + message
+		}
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return false
+	}
 	if err != nil {
-		log.Error("error while incrementing requests counter",
-			slog.String("alias", alias),
+		log.Error("error occurred while checking url",
+			slog.String("url", longUrl),
 			sl.Err(err),
 		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't check url")
+		return false
 	}
 
-	log.Debug("redirected",
-		slog.String("url", url.LongURL),
-		slog.String("alias", alias),
-	)
-	ctx.Redirect(http.StatusPermanentRedirect, url.LongURL)
+	return true
 }
 
 // validateUrl validates URL and return validated email and boolean is email valid.
+// URL may contain one path placeholder in its path, it's replaced by the path after alias on redirect.
 func validateUrl(rawUrl string) (string, bool) {
-	parsedUrl, err := neturl.ParseRequestURI(rawUrl)
+	if strings.Count(rawUrl, passthrough.PathPlaceholder) > 1 || strings.Contains(rawUrl, pathMarker) {
+		return "", false
+	}
+
+	parsedUrl, err := neturl.ParseRequestURI(strings.Replace(rawUrl, passthrough.PathPlaceholder, pathMarker, 1))
 	if err != nil {
 		return "", false
 	}
-	return parsedUrl.String(), true
+
+	validated := parsedUrl.String()
+	if strings.Contains(validated, pathMarker) {
+		if !strings.Contains(parsedUrl.EscapedPath(), pathMarker) || strings.Contains(parsedUrl.Host, pathMarker) {
+			return "", false // placeholder in query or fragment could inject parameters
+		}
+		validated = strings.Replace(validated, pathMarker, passthrough.PathPlaceholder, 1)
+	}
+	return validated, true
+}
+
+// referrerPolicies are values of Referrer-Policy header.
+var referrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+// validateRedirectOptions validates optional redirect status code and headers of url and returns the reason if they are invalid.
+// Empty headers are valid, they remove the header.
+func validateRedirectOptions(redirectCode *int, cacheControl *string, referrerPolicy *string, robotsTag *string) (string, bool) {
+	if redirectCode != nil {
+		switch *redirectCode {
+		case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			return "redirect_code must be 301, 302, 307 or 308", false
+		}
+	}
+	if cacheControl != nil && !isValidHeaderValue(*cacheControl) {
+		return "cache_control must be a valid header value", false
+	}
+	if referrerPolicy != nil && *referrerPolicy != "" && !referrerPolicies[*referrerPolicy] {
+		return "referrer_policy must be a valid Referrer-Policy value", false
+	}
+	if robotsTag != nil && !isValidHeaderValue(*robotsTag) {
+		return "robots_tag must be a valid header value", false
+	}
+	return "", true
+}
+
+// validateDescription checks lengths of title and notes of url, nil values aren't checked.
+// If they are too long, the function returns a message describing the problem and false.
+func validateDescription(title *string, notes *string) (string, bool) {
+	if title != nil && utf8.RuneCountInString(*title) > MaxTitleLength {
+		return fmt.Sprintf("title can't be longer than %d characters", MaxTitleLength), false
+	}
+	if notes != nil && utf8.RuneCountInString(*notes) > MaxNotesLength {
+		return fmt.Sprintf("notes can't be longer than %d characters", MaxNotesLength), false
+	}
+	return "", true
+}
+
+// isValidHeaderValue checks that the value is printable ASCII and fits in database.
+func isValidHeaderValue(value string) bool {
+	if len(value) > 255 {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < ' ' || value[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateExpiration validates optional url lifetime settings and returns the reason if they are invalid.
+func validateExpiration(expiresAt *time.Time, maxRedirects *int) (string, bool) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "expires_at must be in the future", false
+	}
+	if maxRedirects != nil && *maxRedirects <= 0 {
+		return "max_redirects must be positive", false
+	}
+	return "", true
 }
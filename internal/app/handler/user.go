@@ -4,15 +4,31 @@ import (
 	"backend/internal/app/middleware"
 	"backend/internal/app/request"
 	"backend/internal/app/response"
+	"backend/internal/lib/cursor"
 	"backend/internal/lib/logger/sl"
 	"backend/internal/service/repository"
+	repoUrl "backend/internal/service/repository/postgres/url"
 	pgUser "backend/internal/service/repository/postgres/user"
 	"backend/pkg/requestid"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"log/slog"
 	"net/http"
 	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultUrlsPageSize is the number of user urls returned in one page, if limit isn't provided.
+	DefaultUrlsPageSize = 100
+	// MaxUrlsPageSize is the maximum number of user urls returned in one page.
+	MaxUrlsPageSize = 1000
+	// DefaultUrlsSort is the sort of user urls, if sort isn't provided: the newest first.
+	DefaultUrlsSort = "-created_at"
 )
 
 // GetUser       Get user's information.
@@ -131,6 +147,12 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 		response.SendError(ctx, http.StatusNotFound, "user not found")
 		return
 	}
+	if errors.Is(err, repository.ErrUserAlreadyExists) {
+		field := repository.ConflictField(err)
+		log.Debug("user already exists", slog.String("field", field))
+		response.SendConflictError(ctx, field+" already taken", field)
+		return
+	}
 	if err != nil {
 		log.Error("error occurred while updating user",
 			slog.String("id", userID),
@@ -169,7 +191,18 @@ func (h *Handler) DeleteUser(ctx *gin.Context) {
 
 	userID := ctx.GetString(middleware.ContextUserID)
 
-	err := h.service.Repository.User.Delete(ctx, userID)
+	// urls are deleted along with the user, so their aliases are got beforehand to invalidate the cache
+	aliases, err := h.service.Repository.User.GetUrlAliases(ctx, userID)
+	if err != nil {
+		log.Error("error occurred while getting user url aliases",
+			slog.String("id", userID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't delete user")
+		return
+	}
+
+	err = h.service.Repository.User.Delete(ctx, userID)
 	if errors.Is(err, repository.ErrUserNotFound) {
 		log.Info("user not found")
 		response.SendError(ctx, http.StatusNotFound, "user not found")
@@ -184,20 +217,36 @@ func (h *Handler) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
+	for domainID, domainAliases := range aliases {
+		h.invalidateUrlCache(ctx, log, domainID, domainAliases...)
+	}
+
 	ctx.Status(http.StatusOK)
 	log.Info("user deleted",
 		slog.String("id", userID),
 	)
 }
 
-// GetUserUrls   Gets all url documents assigned to given UserID.
+// GetUserUrls   Gets url documents assigned to given UserID page by page.
 // @Summary      Get URLs
 // @Security     AccessToken
-// @Description  Get all URLs created by user
+// @Description  Get URLs created by user page by page, optionally sorted and filtered.
+// @Description  Total count of matching URLs is returned in X-Total-Count header, cursor of the next page
+// @Description  in X-Next-Cursor header and link to it in Link header.
 // @Tags         user
-// @Param        id path string true "id"
+// @Param        id            path  string true  "id"
+// @Param        limit         query int    false "page size, 100 by default, up to 1000"
+// @Param        cursor        query string false "cursor of the page from X-Next-Cursor header"
+// @Param        sort          query string false "created_at, redirects or alias, prefixed by - for descending order; -created_at by default"
+// @Param        tag           query string false "name of tag"
+// @Param        folder        query string false "id of folder"
+// @Param        subfolders    query bool   false "include URLs of subfolders"
+// @Param        created_from  query string false "RFC 3339 date, inclusive"
+// @Param        created_to    query string false "RFC 3339 date, exclusive"
+// @Param        min_redirects query int    false "minimum number of redirects"
 // @Produce      json
 // @Success      200  {array}         response.URL
+// @Failure      400  {object}        response.Error
 // @Failure      401  {object}        response.Error
 // @Failure      500  {object}        response.Error
 // @Router       /user/{id}/urls      [get]
@@ -207,9 +256,16 @@ func (h *Handler) GetUserUrls(ctx *gin.Context) {
 		slog.String("request_id", requestid.Get(ctx)),
 	)
 
+	filter, page, message, ok := parseUrlListQuery(ctx)
+	if !ok {
+		log.Debug("provided list query is invalid", slog.String("reason", message))
+		response.SendError(ctx, http.StatusBadRequest, message)
+		return
+	}
+
 	id := ctx.GetString(middleware.ContextUserID)
 
-	urlDocs, err := h.service.Repository.User.GetUrlsList(ctx, id)
+	urlDocs, more, err := h.service.Repository.User.GetUrlsList(ctx, id, filter, page)
 	if err != nil {
 		log.Error("error occurred while getting user urls",
 			slog.String("id", id),
@@ -219,12 +275,37 @@ func (h *Handler) GetUserUrls(ctx *gin.Context) {
 		return
 	}
 
+	total, err := h.service.Repository.User.CountUrls(ctx, id, filter)
+	if err != nil {
+		log.Error("error occurred while counting user urls",
+			slog.String("id", id),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get urls")
+		return
+	}
+
+	ctx.Header("X-Total-Count", strconv.Itoa(total))
+	if more {
+		last := urlDocs[len(urlDocs)-1]
+		next := cursor.Cursor{
+			Sort: ctx.DefaultQuery("sort", DefaultUrlsSort),
+			Key:  page.KeyOf(last),
+			ID:   last.ID,
+		}.Encode()
+
+		link := *ctx.Request.URL
+		query := link.Query()
+		query.Set("cursor", next)
+		link.RawQuery = query.Encode()
+
+		ctx.Header("X-Next-Cursor", next)
+		ctx.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, link.RequestURI()))
+	}
+
 	urls := make([]response.URL, len(urlDocs))
 	for i, url := range urlDocs {
-		urls[i].ID = url.ID
-		urls[i].Url = url.LongURL
-		urls[i].Alias = url.ShortURL
-		urls[i].Redirects = url.Redirects
+		urls[i] = toUrlResponse(url)
 	}
 	if len(urls) == 0 {
 		ctx.Status(http.StatusNoContent)
@@ -232,6 +313,118 @@ func (h *Handler) GetUserUrls(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, urls)
 }
 
+// toUrlResponse converts an url from repository to response one.
+func toUrlResponse(url repoUrl.URL) response.URL {
+	u := response.URL{
+		ID:               url.ID,
+		Url:              url.LongURL,
+		Alias:            url.ShortURL,
+		Redirects:        url.Redirects,
+		ExpiresAt:        url.ExpiresAt,
+		MaxRedirects:     url.MaxRedirects,
+		Protected:        url.IsPasswordProtected(),
+		RedirectCode:     url.GetRedirectCode(),
+		CacheControl:     url.CacheControl,
+		ReferrerPolicy:   url.ReferrerPolicy,
+		RobotsTag:        url.RobotsTag,
+		QueryPassthrough: url.QueryPassthrough,
+		Title:            url.Title,
+		Notes:            url.Notes,
+		Tags:             []string(url.Tags),
+	}
+	if u.Tags == nil {
+		u.Tags = []string{}
+	}
+	if url.Domain != nil {
+		u.Domain = *url.Domain
+	}
+	if url.FolderID != nil {
+		u.FolderID = *url.FolderID
+	}
+	return u
+}
+
+// parseUrlListQuery parses filter and page of user urls from query parameters.
+// If parameters are invalid, the function returns a message describing the problem and false.
+func parseUrlListQuery(ctx *gin.Context) (pgUser.UrlFilter, pgUser.UrlPage, string, bool) {
+	filter := pgUser.UrlFilter{
+		Tag:      ctx.Query("tag"),
+		FolderID: ctx.Query("folder"),
+	}
+	page := pgUser.UrlPage{Limit: DefaultUrlsPageSize}
+
+	var err error
+	if filter.FolderID != "" {
+		if _, err = uuid.Parse(filter.FolderID); err != nil {
+			return filter, page, "folder is invalid", false
+		}
+	}
+	if subfolders := ctx.Query("subfolders"); subfolders != "" {
+		if filter.Subfolders, err = strconv.ParseBool(subfolders); err != nil {
+			return filter, page, "subfolders must be a boolean", false
+		}
+	}
+	if filter.CreatedFrom, err = parseTimeQuery(ctx, "created_from"); err != nil {
+		return filter, page, "created_from must be a RFC 3339 date", false
+	}
+	if filter.CreatedTo, err = parseTimeQuery(ctx, "created_to"); err != nil {
+		return filter, page, "created_to must be a RFC 3339 date", false
+	}
+	if value := ctx.Query("min_redirects"); value != "" {
+		minRedirects, err := strconv.Atoi(value)
+		if err != nil || minRedirects < 0 {
+			return filter, page, "min_redirects must be a non-negative integer", false
+		}
+		filter.MinRedirects = minRedirects
+	}
+
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxUrlsPageSize {
+			return filter, page, fmt.Sprintf("limit must be between 1 and %d", MaxUrlsPageSize), false
+		}
+		page.Limit = limit
+	}
+
+	sort := ctx.DefaultQuery("sort", DefaultUrlsSort)
+	page.Sort, page.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	if !pgUser.IsValidSort(page.Sort) {
+		return filter, page, "sort must be one of: created_at, redirects, alias, optionally prefixed by -", false
+	}
+
+	if value := ctx.Query("cursor"); value != "" {
+		c, err := cursor.Decode(value)
+		if err != nil {
+			return filter, page, err.Error(), false
+		}
+		if c.Sort != sort {
+			return filter, page, "cursor doesn't match sort", false
+		}
+		if _, err = uuid.Parse(c.ID); err != nil || !pgUser.IsValidKey(page.Sort, c.Key) {
+			return filter, page, cursor.ErrInvalidCursor.Error(), false
+		}
+		page.AfterKey, page.AfterID = c.Key, c.ID
+	}
+
+	return filter, page, "", true
+}
+
+// parseTimeQuery parses an optional RFC 3339 time from the query parameter and converts it to UTC.
+func parseTimeQuery(ctx *gin.Context, param string) (*time.Time, error) {
+	value := ctx.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+
+	return &t, nil
+}
+
 // checkEmailValidity checks is email valid.
 func checkEmailValidity(email string) bool {
 	_, err := mail.ParseAddress(email)
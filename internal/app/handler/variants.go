@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"backend/internal/app/request"
+	"backend/internal/app/response"
+	"backend/internal/lib/logger/sl"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"backend/internal/service/variants"
+	"backend/pkg/requestid"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// VariantCookiePrefix is a prefix of cookies, which keep variants of sticky urls. It's followed by url ID.
+	VariantCookiePrefix = "ms_variant_"
+	// VariantCookieMaxAge is a lifetime of variant cookies.
+	VariantCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// GetUrlVariants Returns destination variants of an URL.
+// @Summary       Get URL variants
+// @Description   Returns weighted destination variants of an URL for A/B tests
+// @Security      AccessToken
+// @Tags          url
+// @Param         id path string true "id"
+// @Produce       json
+// @Success       200  {object}      response.UrlVariants
+// @Failure       401  {object}      response.Error
+// @Failure       403  {object}      response.Error
+// @Failure       404  {object}      response.Error
+// @Failure       500  {object}      response.Error
+// @Router        /url/{id}/variants [get]
+func (h *Handler) GetUrlVariants(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.GetUrlVariants"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	url, ok := h.getUrl(ctx, log)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toVariantsResponse(url.Variants, url.VariantsSticky))
+}
+
+// SetUrlVariants Replaces destination variants of an URL.
+// @Summary       Set URL variants
+// @Description   Replaces weighted destination variants of an URL. Every visit is redirected to one of variants
+// @Description   with probability proportional to its weight. Sticky variants are kept for visitor in a cookie.
+// @Description   Empty list of variants removes them, so visits are redirected to the URL again.
+// @Security      AccessToken
+// @Tags          url
+// @Accept        json
+// @Produce       json
+// @Param         id    path string true "id"
+// @Param         input body request.UrlVariants true "Variants"
+// @Success       200  {object}      response.UrlVariants
+// @Failure       400  {object}      response.Error
+// @Failure       401  {object}      response.Error
+// @Failure       403  {object}      response.Error
+// @Failure       404  {object}      response.Error
+// @Failure       500  {object}      response.Error
+// @Router        /url/{id}/variants [put]
+func (h *Handler) SetUrlVariants(ctx *gin.Context) {
+	log := h.log.With(
+		slog.String("op", "handler.SetUrlVariants"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	var body request.UrlVariants
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Debug("error occurred while decode request body", sl.Err(err))
+		response.SendInvalidRequestBodyError(ctx)
+		return
+	}
+
+	list := make(variants.List, len(body.Variants))
+	for i, v := range body.Variants {
+		longUrl, ok := validateUrl(v.Url)
+		if !ok {
+			response.SendError(ctx, http.StatusBadRequest, fmt.Sprintf("url of variant %d is invalid", i+1))
+			return
+		}
+		if !h.checkDestination(ctx, log, longUrl, fmt.Sprintf("variant %d", i+1)) {
+			return
+		}
+		list[i] = variants.Variant{ID: v.ID, Url: longUrl, Weight: v.Weight}
+	}
+
+	if err := list.Normalize(); err != nil {
+		log.Debug("provided variants are invalid", sl.Err(err))
+		response.SendError(ctx, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "variants: "))
+		return
+	}
+
+	urlID := ctx.Param("id")
+
+	url, err := h.service.Repository.Url.SetVariants(ctx, urlID, list, body.Sticky)
+	if repoUrl.IsErrUrlNotFound(err) {
+		response.SendError(ctx, http.StatusNotFound, "url with this id not found")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while saving url variants",
+			slog.String("id", urlID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't save url variants")
+		return
+	}
+
+	h.invalidateUrlCache(ctx, log, url.GetDomainID(), url.ShortURL)
+
+	ctx.JSON(http.StatusOK, toVariantsResponse(url.Variants, url.VariantsSticky))
+	log.Info("url variants set",
+		slog.String("id", url.ID),
+		slog.Int("variants", len(url.Variants)),
+	)
+}
+
+// pickVariant picks a variant of the url for the visit by weights of variants.
+// Sticky urls keep the picked variant in a cookie and pick it again while it exists.
+// The function returns false if the url has no variants.
+func (h *Handler) pickVariant(ctx *gin.Context, url repoUrl.URL) (variants.Variant, bool) {
+	total := url.Variants.TotalWeight()
+	if total == 0 {
+		return variants.Variant{}, false
+	}
+
+	cookieName := VariantCookiePrefix + url.ID
+	if url.VariantsSticky {
+		if id, err := ctx.Cookie(cookieName); err == nil {
+			if v, ok := url.Variants.Get(id); ok {
+				return v, true
+			}
+		}
+	}
+
+	v, ok := url.Variants.Pick(rand.Intn(total))
+	if ok && url.VariantsSticky {
+		path := "/s/" + neturl.PathEscape(url.ShortURL)
+		ctx.SetCookie(cookieName, v.ID, int(VariantCookieMaxAge.Seconds()), path, "", false, true)
+	}
+
+	return v, ok
+}
+
+// toVariantsResponse converts variants of an url to response ones.
+func toVariantsResponse(list variants.List, sticky bool) response.UrlVariants {
+	vs := make([]response.UrlVariant, len(list))
+	for i, v := range list {
+		vs[i] = response.UrlVariant{
+			ID:     v.ID,
+			Url:    v.Url,
+			Weight: v.Weight,
+		}
+	}
+	return response.UrlVariants{
+		Sticky:   sticky,
+		Variants: vs,
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"backend/internal/lib/logger/sl"
 	"backend/internal/service"
 	"backend/internal/service/repository"
+	repoDomain "backend/internal/service/repository/postgres/domain"
+	repoFolder "backend/internal/service/repository/postgres/folder"
+	repoTag "backend/internal/service/repository/postgres/tag"
 	repoUser "backend/internal/service/repository/postgres/user"
 	"backend/pkg/requestid"
 	"errors"
@@ -155,6 +158,15 @@ func (m *Middleware) UserIdentity(ctx *gin.Context) {
 	}
 }
 
+// TryUserIdentity acts like UserIdentity if Authorization header is provided, and passes the request unauthenticated otherwise.
+func (m *Middleware) TryUserIdentity(ctx *gin.Context) {
+	if ctx.GetHeader(HeaderAuthorization) == "" {
+		ctx.Next()
+		return
+	}
+	m.UserIdentity(ctx)
+}
+
 // CheckOwner middleware checks if user owning URL with ID from parameter.
 func (m *Middleware) CheckOwner(ctx *gin.Context) {
 	log := m.log.With(
@@ -182,13 +194,115 @@ func (m *Middleware) CheckOwner(ctx *gin.Context) {
 		return
 	}
 
-	if *url.UserID != userID {
+	if url.UserID == nil || *url.UserID != userID {
 		response.SendError(ctx, http.StatusForbidden, "not your url")
 		return
 	}
 	ctx.Next()
 }
 
+// CheckDomainOwner middleware checks if user owning domain with ID from parameter.
+func (m *Middleware) CheckDomainOwner(ctx *gin.Context) {
+	log := m.log.With(
+		slog.String("op", "middleware.CheckDomainOwner"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	domainID := ctx.Param("id")
+	userID := ctx.GetString(ContextUserID)
+
+	domain, err := m.service.Repository.Domain.GetByID(ctx, domainID)
+	if repoDomain.IsErrDomainNotFound(err) {
+		log.Debug("domain not found",
+			slog.String("id", domainID),
+		)
+		response.SendError(ctx, http.StatusNotFound, "domain with this id not found")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while getting domain",
+			slog.String("id", domainID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get domain")
+		return
+	}
+
+	if domain.UserID != userID {
+		response.SendError(ctx, http.StatusForbidden, "not your domain")
+		return
+	}
+	ctx.Next()
+}
+
+// CheckTagOwner middleware checks if user owning tag with ID from parameter.
+func (m *Middleware) CheckTagOwner(ctx *gin.Context) {
+	log := m.log.With(
+		slog.String("op", "middleware.CheckTagOwner"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	tagID := ctx.Param("id")
+	userID := ctx.GetString(ContextUserID)
+
+	tag, err := m.service.Repository.Tag.GetByID(ctx, tagID)
+	if repoTag.IsErrTagNotFound(err) {
+		log.Debug("tag not found",
+			slog.String("id", tagID),
+		)
+		response.SendError(ctx, http.StatusNotFound, "tag with this id not found")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while getting tag",
+			slog.String("id", tagID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get tag")
+		return
+	}
+
+	if tag.UserID != userID {
+		response.SendError(ctx, http.StatusForbidden, "not your tag")
+		return
+	}
+	ctx.Next()
+}
+
+// CheckFolderOwner middleware checks if user owning folder with ID from parameter.
+func (m *Middleware) CheckFolderOwner(ctx *gin.Context) {
+	log := m.log.With(
+		slog.String("op", "middleware.CheckFolderOwner"),
+		slog.String("request_id", requestid.Get(ctx)),
+	)
+
+	folderID := ctx.Param("id")
+	userID := ctx.GetString(ContextUserID)
+
+	folder, err := m.service.Repository.Folder.GetByID(ctx, folderID)
+	if repoFolder.IsErrFolderNotFound(err) {
+		log.Debug("folder not found",
+			slog.String("id", folderID),
+		)
+		response.SendError(ctx, http.StatusNotFound, "folder with this id not found")
+		return
+	}
+	if err != nil {
+		log.Error("error occurred while getting folder",
+			slog.String("id", folderID),
+			sl.Err(err),
+		)
+		response.SendError(ctx, http.StatusInternalServerError, "can't get folder")
+		return
+	}
+
+	if folder.UserID != userID {
+		response.SendError(ctx, http.StatusForbidden, "not your folder")
+		return
+	}
+	ctx.Next()
+}
+
 // CheckMe middleware checks if UserID from context (authenticated user id) completely equals to ID from parameter.
 func (m *Middleware) CheckMe(ctx *gin.Context) {
 	if ctx.GetString(ContextUserID) != ctx.Param("id") {
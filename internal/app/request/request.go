@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 type UserCreate struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
@@ -19,11 +21,90 @@ type UserLogin struct {
 }
 
 type URL struct {
+	Url              string     `json:"url"`
+	Alias            string     `json:"alias,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	MaxRedirects     *int       `json:"max_redirects,omitempty"`
+	Password         string     `json:"password,omitempty"`
+	Domain           string     `json:"domain,omitempty"`        // verified custom domain of user, default domain if empty
+	RedirectCode     *int       `json:"redirect_code,omitempty"` // 301, 302, 307 or 308, 308 by default
+	CacheControl     string     `json:"cache_control,omitempty"`
+	ReferrerPolicy   string     `json:"referrer_policy,omitempty"`
+	RobotsTag        string     `json:"robots_tag,omitempty"`
+	QueryPassthrough string     `json:"query_passthrough,omitempty"` // off, keep, override or append, off by default
+	FolderID         string     `json:"folder_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Notes            string     `json:"notes,omitempty"`
+}
+
+type BatchUrl struct {
 	Url   string `json:"url"`
 	Alias string `json:"alias,omitempty"`
 }
 
 type UrlUpdate struct {
-	Url   string `json:"url,omitempty"`
-	Alias string `json:"alias,omitempty"`
+	Url               string     `json:"url,omitempty"`
+	Alias             string     `json:"alias,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxRedirects      *int       `json:"max_redirects,omitempty"`
+	ClearExpiresAt    bool       `json:"clear_expires_at,omitempty"`    // removes the expiration date
+	ClearMaxRedirects bool       `json:"clear_max_redirects,omitempty"` // removes the redirects budget
+	Password          *string    `json:"password,omitempty"`            // empty string removes the password
+	RedirectCode      *int       `json:"redirect_code,omitempty"`
+	CacheControl      *string    `json:"cache_control,omitempty"` // empty string removes the header, and so do other headers
+	ReferrerPolicy    *string    `json:"referrer_policy,omitempty"`
+	RobotsTag         *string    `json:"robots_tag,omitempty"`
+	QueryPassthrough  *string    `json:"query_passthrough,omitempty"`
+	FolderID          *string    `json:"folder_id,omitempty"` // empty string moves the url to no folder
+	Title             *string    `json:"title,omitempty"`
+	Notes             *string    `json:"notes,omitempty"`
+}
+
+type UrlRule struct {
+	Countries []string `json:"countries,omitempty"` // ISO 3166-1 alpha-2 codes, e.g. US, DE
+	OS        []string `json:"os,omitempty"`        // iOS, Android, Windows, ChromeOS, macOS or Linux
+	Devices   []string `json:"devices,omitempty"`   // desktop, mobile, tablet or bot
+	Browsers  []string `json:"browsers,omitempty"`  // e.g. Chrome, Safari, Firefox
+	Url       string   `json:"url"`
+}
+
+type UrlVariant struct {
+	ID     string `json:"id,omitempty"` // a, b, c and so on by position if empty
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+type UrlVariants struct {
+	Sticky   bool         `json:"sticky"` // visitor gets the same variant on every visit
+	Variants []UrlVariant `json:"variants"`
+}
+
+type UrlUnlock struct {
+	Password string `json:"password" form:"password"`
+}
+
+type TagCreate struct {
+	Name string `json:"name"`
+}
+
+type TagUpdate struct {
+	Name string `json:"name"`
+}
+
+type FolderCreate struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"` // top-level folder if empty
+}
+
+type FolderUpdate struct {
+	Name     *string `json:"name,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"` // empty string moves the folder to the top level
+}
+
+type UrlIDs struct {
+	IDs []string `json:"ids"`
+}
+
+type DomainCreate struct {
+	Host string `json:"host"`
 }
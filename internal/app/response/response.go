@@ -3,10 +3,12 @@ package response
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"time"
 )
 
 type Error struct {
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"` // field of request, which value conflicts with existing data
 }
 
 type User struct {
@@ -16,22 +18,216 @@ type User struct {
 }
 
 type URL struct {
-	ID        string `json:"id"`
-	Url       string `json:"url"`
-	Alias     string `json:"alias"`
-	Redirects int    `json:"redirects"`
+	ID               string     `json:"id"`
+	Url              string     `json:"url"`
+	Alias            string     `json:"alias"`
+	Redirects        int        `json:"redirects"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	MaxRedirects     *int       `json:"max_redirects,omitempty"`
+	Protected        bool       `json:"password_protected"`
+	Domain           string     `json:"domain,omitempty"`
+	RedirectCode     int        `json:"redirect_code"`
+	CacheControl     string     `json:"cache_control,omitempty"`
+	ReferrerPolicy   string     `json:"referrer_policy,omitempty"`
+	RobotsTag        string     `json:"robots_tag,omitempty"`
+	QueryPassthrough string     `json:"query_passthrough"`
+	FolderID         string     `json:"folder_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Notes            string     `json:"notes,omitempty"`
+	Tags             []string   `json:"tags"`
+}
+
+type UrlExport struct {
+	ID               string       `json:"id"`
+	UserID           string       `json:"user_id"`
+	Url              string       `json:"url"`
+	Alias            string       `json:"alias"`
+	Domain           string       `json:"domain,omitempty"`
+	Redirects        int          `json:"redirects"`
+	CreatedAt        time.Time    `json:"created_at"`
+	ExpiresAt        *time.Time   `json:"expires_at,omitempty"`
+	MaxRedirects     *int         `json:"max_redirects,omitempty"`
+	Protected        bool         `json:"password_protected"`
+	RedirectCode     int          `json:"redirect_code"`
+	CacheControl     string       `json:"cache_control,omitempty"`
+	ReferrerPolicy   string       `json:"referrer_policy,omitempty"`
+	RobotsTag        string       `json:"robots_tag,omitempty"`
+	QueryPassthrough string       `json:"query_passthrough"`
+	Title            string       `json:"title,omitempty"`
+	Notes            string       `json:"notes,omitempty"`
+	Rules            []UrlRule    `json:"rules,omitempty"`
+	Variants         []UrlVariant `json:"variants,omitempty"`
+	VariantsSticky   bool         `json:"variants_sticky"`
+	FolderID         string       `json:"folder_id,omitempty"`
+	Tags             []string     `json:"tags"`
+}
+
+type UrlPreview struct {
+	Alias     string       `json:"alias"`
+	Url       string       `json:"url,omitempty"`      // hidden for password protected urls, and so are rules and variants
+	Rules     []UrlRule    `json:"rules,omitempty"`    // destinations of visitors matching the rules, instead of url
+	Variants  []UrlVariant `json:"variants,omitempty"` // destinations picked by weights, instead of url
+	Domain    string       `json:"domain,omitempty"`
+	Redirects int          `json:"redirects"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+	Protected bool         `json:"password_protected"`
+	Owner     string       `json:"owner,omitempty"` // username of url's owner
 }
 
 type UrlCreated struct {
-	ID    string `json:"id"`
+	ID               string     `json:"id"`
+	Url              string     `json:"url"`
+	Alias            string     `json:"alias"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	MaxRedirects     *int       `json:"max_redirects,omitempty"`
+	Protected        bool       `json:"password_protected"`
+	Domain           string     `json:"domain,omitempty"`
+	RedirectCode     int        `json:"redirect_code"`
+	CacheControl     string     `json:"cache_control,omitempty"`
+	ReferrerPolicy   string     `json:"referrer_policy,omitempty"`
+	RobotsTag        string     `json:"robots_tag,omitempty"`
+	QueryPassthrough string     `json:"query_passthrough"`
+	FolderID         string     `json:"folder_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Notes            string     `json:"notes,omitempty"`
+}
+
+type BatchRow struct {
+	Row   int    `json:"row"`
+	ID    string `json:"id,omitempty"`
 	Url   string `json:"url"`
+	Alias string `json:"alias,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type UrlBatch struct {
+	Created int        `json:"created"`
+	Failed  int        `json:"failed"`
+	Results []BatchRow `json:"results"`
+}
+
+type ImportError struct {
+	Line  int    `json:"line"`
 	Alias string `json:"alias"`
+	Url   string `json:"url"`
+	Error string `json:"error"`
+}
+
+type UrlImport struct {
+	Total     int           `json:"total"`
+	Imported  int           `json:"imported"`
+	Conflicts int           `json:"conflicts"`
+	Invalid   int           `json:"invalid"`
+	Errors    []ImportError `json:"errors"` // first MaxImportErrors not imported records
+}
+
+type AliasAvailability struct {
+	Alias     string `json:"alias"` // alias as it would be stored
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // why the alias isn't available
+}
+
+type AliasSuggestions struct {
+	Suggestions []string `json:"suggestions"`
 }
 
 type UrlUpdated struct {
-	ID    string `json:"id"`
-	Url   string `json:"url,omitempty"`
-	Alias string `json:"alias,omitempty"`
+	ID               string     `json:"id"`
+	Url              string     `json:"url,omitempty"`
+	Alias            string     `json:"alias,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	MaxRedirects     *int       `json:"max_redirects,omitempty"`
+	Protected        bool       `json:"password_protected"`
+	RedirectCode     int        `json:"redirect_code"`
+	CacheControl     string     `json:"cache_control,omitempty"`
+	ReferrerPolicy   string     `json:"referrer_policy,omitempty"`
+	RobotsTag        string     `json:"robots_tag,omitempty"`
+	QueryPassthrough string     `json:"query_passthrough"`
+	FolderID         string     `json:"folder_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Notes            string     `json:"notes,omitempty"`
+}
+
+type UrlRule struct {
+	ID        string   `json:"id"`
+	Countries []string `json:"countries,omitempty"`
+	OS        []string `json:"os,omitempty"`
+	Devices   []string `json:"devices,omitempty"`
+	Browsers  []string `json:"browsers,omitempty"`
+	Url       string   `json:"url"`
+}
+
+type UrlVariant struct {
+	ID     string `json:"id"`
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+type UrlVariants struct {
+	Sticky   bool         `json:"sticky"`
+	Variants []UrlVariant `json:"variants"`
+}
+
+type StatsEntry struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type UrlStats struct {
+	ID        string       `json:"id"`
+	Alias     string       `json:"alias"`
+	Total     int          `json:"total"`
+	Referrers []StatsEntry `json:"referrers"`
+	Countries []StatsEntry `json:"countries"`
+	Devices   []StatsEntry `json:"devices"`
+	Browsers  []StatsEntry `json:"browsers"`
+	Variants  []StatsEntry `json:"variants"` // clicks of urls without variants are counted as "none"
+}
+
+type TimeSeriesPoint struct {
+	Time   time.Time `json:"time"`
+	Clicks int       `json:"clicks"`
+}
+
+type TimeSeries struct {
+	Interval string            `json:"interval"`
+	TimeZone string            `json:"tz"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Total    int               `json:"total"`
+	Points   []TimeSeriesPoint `json:"points"`
+}
+
+type DomainRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Urls int    `json:"urls"`
+}
+
+type Folder struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+	Urls     int    `json:"urls"` // urls right in the folder, without subfolders
+}
+
+type UrlsAffected struct {
+	Affected int64 `json:"affected"` // urls changed by the request
+}
+
+type Domain struct {
+	ID                 string       `json:"id"`
+	Host               string       `json:"host"`
+	Verified           bool         `json:"verified"`
+	VerifiedAt         *time.Time   `json:"verified_at,omitempty"`
+	VerificationRecord DomainRecord `json:"verification_record"`
 }
 
 type TokenPair struct {
@@ -49,7 +245,41 @@ func SendAuthFailedError(ctx *gin.Context) {
 	SendError(ctx, http.StatusUnauthorized, "auth failed")
 }
 
+// SendPageError sends an error response with some status code: an HTML page for browsers and a JSON body otherwise.
+func SendPageError(ctx *gin.Context, statusCode int, message string) {
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		SendError(ctx, statusCode, message)
+		return
+	}
+
+	ctx.Abort()
+	ctx.HTML(statusCode, "error.html", gin.H{
+		"Status":  statusCode,
+		"Title":   http.StatusText(statusCode),
+		"Message": message,
+	})
+}
+
+// SendPasswordRequired sends a 401 Unauthorized response for password protected url:
+// a password form for browsers and a JSON body otherwise.
+func SendPasswordRequired(ctx *gin.Context, message string) {
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		SendError(ctx, http.StatusUnauthorized, message)
+		return
+	}
+
+	ctx.Abort()
+	ctx.HTML(http.StatusUnauthorized, "password.html", gin.H{
+		"Message": message,
+	})
+}
+
 // SendError sends an error response with some status code and message field.
 func SendError(ctx *gin.Context, statusCode int, message string) {
 	ctx.AbortWithStatusJSON(statusCode, Error{Message: message})
 }
+
+// SendConflictError sends a 409 error response with message and the field, which value is already taken.
+func SendConflictError(ctx *gin.Context, message string, field string) {
+	ctx.AbortWithStatusJSON(http.StatusConflict, Error{Message: message, Field: field})
+}
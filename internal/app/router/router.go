@@ -3,6 +3,7 @@ package router
 import (
 	"backend/internal/app/handler"
 	"backend/internal/app/middleware"
+	"backend/internal/app/templates"
 	"backend/internal/config"
 	"backend/internal/lib/logger/format"
 	"backend/internal/service"
@@ -38,6 +39,7 @@ func New(cfg *config.Config, log *slog.Logger, service *service.Service) *Router
 // InitRoutes create a new routes list for handler.
 func (r *Router) InitRoutes() *gin.Engine {
 	router := gin.New()
+	router.SetHTMLTemplate(templates.New())
 
 	router.Use(gin.Recovery())
 	router.Use(requestid.New)
@@ -46,7 +48,10 @@ func (r *Router) InitRoutes() *gin.Engine {
 
 	// router.GET("/:alias", r.handler.Redirect)
 
-	router.GET("/s/:alias", r.handler.Redirect)
+	router.GET("/s/:alias", r.handler.Redirect) // also serves QR codes of aliases with .qr suffix and previews with + suffix
+	router.POST("/s/:alias", r.handler.Unlock)
+	router.GET("/s/:alias/*path", r.handler.Redirect) // path after alias is passed to urls with {path} placeholder
+	router.POST("/s/:alias/*path", r.handler.Unlock)
 
 	api := router.Group("/api")
 	{
@@ -64,9 +69,50 @@ func (r *Router) InitRoutes() *gin.Engine {
 
 		url := api.Group("/url") // TODO: After tests, move user identity here
 		{
-			url.POST("/", r.handler.CreateUrl)
+			url.POST("/", r.middleware.TryUserIdentity, r.handler.CreateUrl)
+			url.POST("/batch", r.middleware.TryUserIdentity, r.handler.CreateUrlsBatch)
+			url.POST("/import", r.middleware.UserIdentity, r.handler.ImportUrls)
+			url.GET("/search", r.middleware.UserIdentity, r.handler.SearchUrls)
+			url.GET("/alias/suggest", r.middleware.TryUserIdentity, r.handler.SuggestAliases)
+			url.GET("/alias/:alias/availability", r.middleware.TryUserIdentity, r.handler.GetAliasAvailability)
 			url.PATCH("/:id", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.UpdateUrl)
 			url.DELETE("/:id", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.DeleteUrl)
+			url.GET("/:id/qr", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.GetUrlQR)
+			url.GET("/:id/stats", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.GetUrlStats)
+			url.GET("/:id/stats/timeseries", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.GetUrlTimeSeries)
+			url.GET("/:id/rules", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.GetUrlRules)
+			url.PUT("/:id/rules", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.SetUrlRules)
+			url.POST("/:id/rules", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.AddUrlRule)
+			url.DELETE("/:id/rules/:rule_id", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.DeleteUrlRule)
+			url.GET("/:id/variants", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.GetUrlVariants)
+			url.PUT("/:id/variants", r.middleware.UserIdentity, r.middleware.CheckOwner, r.handler.SetUrlVariants)
+		}
+
+		domain := api.Group("/domain", r.middleware.UserIdentity)
+		{
+			domain.POST("/", r.handler.CreateDomain)
+			domain.GET("/", r.handler.GetDomains)
+			domain.POST("/:id/verify", r.middleware.CheckDomainOwner, r.handler.VerifyDomain)
+			domain.DELETE("/:id", r.middleware.CheckDomainOwner, r.handler.DeleteDomain)
+		}
+
+		tag := api.Group("/tag", r.middleware.UserIdentity)
+		{
+			tag.POST("/", r.handler.CreateTag)
+			tag.GET("/", r.handler.GetTags)
+			tag.PATCH("/:id", r.middleware.CheckTagOwner, r.handler.UpdateTag)
+			tag.DELETE("/:id", r.middleware.CheckTagOwner, r.handler.DeleteTag)
+			tag.POST("/:id/urls", r.middleware.CheckTagOwner, r.handler.TagUrls)
+			tag.DELETE("/:id/urls", r.middleware.CheckTagOwner, r.handler.UntagUrls)
+		}
+
+		folder := api.Group("/folder", r.middleware.UserIdentity)
+		{
+			folder.POST("/", r.handler.CreateFolder)
+			folder.GET("/", r.handler.GetFolders)
+			folder.PATCH("/:id", r.middleware.CheckFolderOwner, r.handler.UpdateFolder)
+			folder.DELETE("/:id", r.middleware.CheckFolderOwner, r.handler.DeleteFolder)
+			folder.POST("/:id/urls", r.middleware.CheckFolderOwner, r.handler.MoveUrlsToFolder)
 		}
 
 		user := api.Group("/user")
@@ -77,6 +123,8 @@ func (r *Router) InitRoutes() *gin.Engine {
 			user.PATCH("/:id", r.middleware.UserIdentity, r.middleware.CheckMe, r.handler.UpdateUser)
 			user.DELETE("/:id", r.middleware.UserIdentity, r.middleware.CheckMe, r.handler.DeleteUser)
 			user.GET("/:id/urls", r.middleware.UserIdentity, r.middleware.CheckMe, r.handler.GetUserUrls)
+			user.GET("/:id/urls/export", r.middleware.UserIdentity, r.middleware.CheckMe, r.handler.ExportUserUrls)
+			user.GET("/:id/urls/stats/timeseries", r.middleware.UserIdentity, r.middleware.CheckMe, r.handler.GetUserUrlsTimeSeries)
 		}
 	}
 
@@ -0,0 +1,20 @@
+package templates
+
+import (
+	"embed"
+	"html/template"
+	"strings"
+)
+
+//go:embed *.html
+var files embed.FS
+
+// funcs are functions available in pages.
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// New parses all embedded HTML pages and returns them as a single template set.
+func New() *template.Template {
+	return template.Must(template.New("").Funcs(funcs).ParseFS(files, "*.html"))
+}
@@ -15,14 +15,21 @@ const (
 )
 
 type Config struct {
-	Env                 string     `yaml:"env" env-required:"true"`
-	HashSalt            string     `yaml:"hash_salt" env-required:"true"`
-	Token               Token      `yaml:"token" env-required:"true"`
-	Cookie              Cookie     `yaml:"cookie"`
-	Postgres            PostgresDB `yaml:"postgres" env-required:"true"`
-	Redis               Redis      `yaml:"redis" env-required:"true"`
-	Server              Server     `yaml:"http" env-required:"true"`
-	ServerDefaultCookie string     `yaml:"server_default_cookie" env-default:"X-Makeshort-Request"`
+	Env                 string          `yaml:"env" env-required:"true"`
+	HashSalt            string          `yaml:"hash_salt" env-required:"true"`
+	BaseURL             string          `yaml:"base_url" env-default:"http://localhost:7531"` // scheme and host of short urls on the default domain
+	Token               Token           `yaml:"token" env-required:"true"`
+	Cookie              Cookie          `yaml:"cookie"`
+	Postgres            PostgresDB      `yaml:"postgres" env-required:"true"`
+	Redis               Redis           `yaml:"redis" env-required:"true"`
+	Cache               Cache           `yaml:"cache"`
+	Alias               Alias           `yaml:"alias"`
+	Destination         Destination     `yaml:"destination"`
+	GeoIP               GeoIP           `yaml:"geoip"`
+	QR                  QR              `yaml:"qr"`
+	RedirectCounter     RedirectCounter `yaml:"redirect_counter"`
+	Server              Server          `yaml:"http" env-required:"true"`
+	ServerDefaultCookie string          `yaml:"server_default_cookie" env-default:"X-Makeshort-Request"`
 }
 
 type Token struct {
@@ -64,10 +71,62 @@ type Redis struct {
 	Password string `yaml:"password"`
 }
 
+type Cache struct {
+	TTL         time.Duration `yaml:"ttl" env-default:"5m"`
+	NegativeTTL time.Duration `yaml:"negative_ttl" env-default:"30s"` // TTL of aliases, which do not exist
+}
+
+type Alias struct {
+	Generator   string      `yaml:"generator" env-default:"base62"` // base62, sequence or words
+	Length      int         `yaml:"length" env-default:"6"`
+	MaxLength   int         `yaml:"max_length" env-default:"10"` // aliases are lengthened up to it, when they collide too often
+	MaxAttempts int         `yaml:"max_attempts" env-default:"5"`
+	Salt        string      `yaml:"salt"` // shuffles alphabet of sequence aliases
+	Policy      AliasPolicy `yaml:"policy"`
+}
+
+// AliasPolicy restricts custom aliases chosen by users.
+type AliasPolicy struct {
+	Charset       string   `yaml:"charset" env-default:"0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-_"`
+	MinLength     int      `yaml:"min_length" env-default:"3"`
+	MaxLength     int      `yaml:"max_length" env-default:"20"`
+	Reserved      []string `yaml:"reserved"`       // reserved in addition to names of routes
+	BlocklistPath string   `yaml:"blocklist_path"` // path to file of words, one per line, which aliases can't contain
+	FoldCase      bool     `yaml:"fold_case"`      // lowercase custom aliases, so aliases can't differ only in case
+}
+
+// Destination restricts urls, which short urls redirect to.
+type Destination struct {
+	Schemes              []string `yaml:"schemes" env-default:"http,https"`
+	AllowedDomains       []string `yaml:"allowed_domains"` // if not empty, only these domains and their subdomains are allowed
+	DeniedDomains        []string `yaml:"denied_domains"`
+	DenylistPath         string   `yaml:"denylist_path"`          // path to file of known-bad domains, one per line
+	ShortDomains         []string `yaml:"short_domains"`          // other hosts of short urls, besides host of base_url and custom domains
+	AllowPrivateNetworks bool     `yaml:"allow_private_networks"` // don't block local names and private IP addresses
+	ResolveHosts         bool     `yaml:"resolve_hosts"`          // also block domains resolving to private IP addresses
+}
+
+type GeoIP struct {
+	Path           string        `yaml:"path"`            // path to MaxMind-format country database, clicks countries aren't resolved if empty
+	ReloadInterval time.Duration `yaml:"reload_interval"` // interval of checking the database file for changes, reloading is disabled if empty or 0
+}
+
+type QR struct {
+	LogoPath string `yaml:"logo_path"` // path to PNG or JPEG logo embedded in QR codes on request, logo is disabled if empty
+}
+
+type RedirectCounter struct {
+	FlushInterval time.Duration `yaml:"flush_interval" env-default:"5s"`
+	FlushSize     int           `yaml:"flush_size" env-default:"1000"`   // number of distinct urls or clicks, which triggers a flush before interval
+	BufferSize    int           `yaml:"buffer_size" env-default:"10000"` // clicks over this size are dropped until next flush
+}
+
 type Server struct {
 	Address     string        `yaml:"address" env-required:"true"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+
+	DebugAddress string `yaml:"debug_address"` // internal listener of /debug/vars metrics, disabled if empty; never expose it publicly
 }
 
 // MustLoad loads config to a new Config instance and return it.
@@ -0,0 +1,39 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+// Cursor points to the last item of a page of a sorted list, so the next page starts right after it.
+// Unlike offsets, cursors don't skip or repeat items when new ones are inserted between requests.
+type Cursor struct {
+	Sort string `json:"s"`  // sort of the list, the cursor is valid only for it
+	Key  string `json:"k"`  // sort key of the item
+	ID   string `json:"id"` // ID of the item, which breaks ties of equal keys
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a cursor returned by Encode.
+// If the string isn't a valid cursor, the function will return an ErrInvalidCursor.
+func Decode(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(b, &c); err != nil || c.Sort == "" || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,37 @@
+package cursor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	valid := Cursor{Sort: "-created_at", Key: "2024-01-02T03:04:05.123456Z", ID: "0b7e2e52-5d3f-4a8c-9f6e-1b2c3d4e5f60"}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    Cursor
+		wantErr error
+	}{
+		{name: "Encoded cursor", s: valid.Encode(), want: valid},
+		{name: "Empty key", s: Cursor{Sort: "alias", ID: "1"}.Encode(), want: Cursor{Sort: "alias", ID: "1"}},
+		{name: "Empty string", s: "", wantErr: ErrInvalidCursor},
+		{name: "Not base64", s: "not a cursor!", wantErr: ErrInvalidCursor},
+		{name: "Not JSON", s: "bm90IGpzb24", wantErr: ErrInvalidCursor},
+		{name: "Missing sort", s: Cursor{Key: "a", ID: "1"}.Encode(), wantErr: ErrInvalidCursor},
+		{name: "Missing ID", s: Cursor{Sort: "alias", Key: "a"}.Encode(), wantErr: ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode(%q) error = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Decode(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
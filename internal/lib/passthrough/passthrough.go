@@ -0,0 +1,112 @@
+package passthrough
+
+import (
+	"errors"
+	neturl "net/url"
+	"strings"
+)
+
+// PathPlaceholder is replaced in long url by the path visited after alias, e.g. /s/gh/some/path.
+const PathPlaceholder = "{path}"
+
+// Policy defines how query of the visited short url is merged into long url.
+type Policy string
+
+const (
+	Off      Policy = "off"      // query of short url is dropped
+	Keep     Policy = "keep"     // query is merged, long url values win on conflicts
+	Override Policy = "override" // query is merged, visited values win on conflicts
+	Append   Policy = "append"   // query is merged, both values are kept on conflicts
+)
+
+var (
+	ErrInvalidPolicy  = errors.New("query_passthrough must be one of: off, keep, override, append")
+	ErrUnexpectedPath = errors.New("passthrough: url doesn't accept a path")
+	ErrInvalidPath    = errors.New("passthrough: path contains dot segments")
+)
+
+// ParsePolicy parses a policy name. Empty name is parsed as Off.
+func ParsePolicy(s string) (Policy, error) {
+	switch Policy(s) {
+	case "":
+		return Off, nil
+	case Off, Keep, Override, Append:
+		return Policy(s), nil
+	default:
+		return "", ErrInvalidPolicy
+	}
+}
+
+// IsTemplate reports whether the long url accepts a path after alias.
+func IsTemplate(longUrl string) bool {
+	return strings.Contains(longUrl, PathPlaceholder)
+}
+
+// Expand returns the destination of long url for the path visited after alias and the query of short url.
+// Path segments are escaped, so they can't change query or fragment of long url.
+func Expand(longUrl string, path string, query neturl.Values, policy Policy) (string, error) {
+	path = strings.Trim(path, "/")
+	if path != "" && !IsTemplate(longUrl) {
+		return "", ErrUnexpectedPath
+	}
+
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, "/")
+	}
+	for i, segment := range segments {
+		if segment == "." || segment == ".." {
+			return "", ErrInvalidPath
+		}
+		segments[i] = neturl.PathEscape(segment)
+	}
+	destination := strings.Replace(longUrl, PathPlaceholder, strings.Join(segments, "/"), 1)
+
+	if policy == "" || policy == Off || len(query) == 0 {
+		return destination, nil
+	}
+
+	u, err := neturl.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = merge(u.RawQuery, query, policy)
+
+	return u.String(), nil
+}
+
+// merge merges query into raw query of long url by policy. Order and encoding of long url parameters are preserved.
+func merge(rawQuery string, query neturl.Values, policy Policy) string {
+	existing, _ := neturl.ParseQuery(rawQuery)
+
+	extra := make(neturl.Values, len(query))
+	for key, values := range query {
+		if _, ok := existing[key]; ok && policy == Keep {
+			continue
+		}
+		extra[key] = values
+	}
+
+	if policy == Override {
+		kept := make([]string, 0)
+		for _, pair := range strings.Split(rawQuery, "&") {
+			key, _, _ := strings.Cut(pair, "=")
+			if unescaped, err := neturl.QueryUnescape(key); err == nil {
+				key = unescaped
+			}
+			if _, ok := query[key]; !ok && pair != "" {
+				kept = append(kept, pair)
+			}
+		}
+		rawQuery = strings.Join(kept, "&")
+	}
+
+	switch {
+	case len(extra) == 0:
+		return rawQuery
+	case rawQuery == "":
+		return extra.Encode()
+	default:
+		return rawQuery + "&" + extra.Encode()
+	}
+}
@@ -0,0 +1,122 @@
+package passthrough
+
+import (
+	"errors"
+	neturl "net/url"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name    string
+		longUrl string
+		path    string
+		query   string
+		policy  Policy
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "Query is dropped when policy is off",
+			longUrl: "https://example.com/a?x=1",
+			query:   "utm_source=x",
+			policy:  Off,
+			want:    "https://example.com/a?x=1",
+		},
+		{
+			name:    "Query is added to url without query",
+			longUrl: "https://example.com/a",
+			query:   "utm_source=x",
+			policy:  Keep,
+			want:    "https://example.com/a?utm_source=x",
+		},
+		{
+			name:    "Long url values win with keep policy",
+			longUrl: "https://example.com/a?z=1&x=1",
+			query:   "x=2&y=3",
+			policy:  Keep,
+			want:    "https://example.com/a?z=1&x=1&y=3",
+		},
+		{
+			name:    "Visited values win with override policy",
+			longUrl: "https://example.com/a?z=1&x=1",
+			query:   "x=2&y=3",
+			policy:  Override,
+			want:    "https://example.com/a?z=1&x=2&y=3",
+		},
+		{
+			name:    "Both values are kept with append policy",
+			longUrl: "https://example.com/a?x=1",
+			query:   "x=2",
+			policy:  Append,
+			want:    "https://example.com/a?x=1&x=2",
+		},
+		{
+			name:    "Fragment stays at the end",
+			longUrl: "https://example.com/a#top",
+			query:   "x=2",
+			policy:  Append,
+			want:    "https://example.com/a?x=2#top",
+		},
+		{
+			name:    "Path replaces placeholder",
+			longUrl: "https://github.com/{path}",
+			path:    "/makeshort/backend/issues",
+			want:    "https://github.com/makeshort/backend/issues",
+		},
+		{
+			name:    "Empty path replaces placeholder",
+			longUrl: "https://github.com/{path}",
+			want:    "https://github.com/",
+		},
+		{
+			name:    "Path segments are escaped",
+			longUrl: "https://example.com/docs/{path}?lang=en",
+			path:    "/a b/c?d=e#f",
+			query:   "x=1",
+			policy:  Keep,
+			want:    "https://example.com/docs/a%20b/c%3Fd=e%23f?lang=en&x=1",
+		},
+		{
+			name:    "Path to url without placeholder",
+			longUrl: "https://example.com/a",
+			path:    "/b",
+			wantErr: ErrUnexpectedPath,
+		},
+		{
+			name:    "Dot segments",
+			longUrl: "https://example.com/docs/{path}",
+			path:    "/a/../../admin",
+			wantErr: ErrInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := neturl.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid test query: %v", err)
+			}
+
+			got, err := Expand(tt.longUrl, tt.path, query, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expand() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for input, want := range map[string]Policy{"": Off, "off": Off, "keep": Keep, "override": Override, "append": Append} {
+		got, err := ParsePolicy(input)
+		if err != nil || got != want {
+			t.Errorf("ParsePolicy(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParsePolicy("merge"); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("ParsePolicy(merge) error = %v, want %v", err, ErrInvalidPolicy)
+	}
+}
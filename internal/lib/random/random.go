@@ -1,21 +1,56 @@
 package random
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"errors"
 )
 
-const chars = "abcdefghijklmnopqrstuvwxuz0123456789"
+const (
+	// Alphanumeric contains lowercase latin letters and digits.
+	Alphanumeric = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// Base62 contains latin letters of both cases and digits.
+	Base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+var ErrInvalidCharset = errors.New("random: charset must contain 1-256 characters")
 
-// Generate generates a random string given length.
+// Generate generates a random string of lowercase letters and digits given length.
+// It panics if the system source of randomness fails, which is not expected on supported platforms.
 func Generate(size int) string {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	chars := []rune(chars)
+	s, err := GenerateFrom(size, Alphanumeric)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// GenerateFrom generates a random string given length from the charset of single-byte characters.
+// Every character of the charset has the same probability, the string is read from crypto/rand.
+func GenerateFrom(size int, charset string) (string, error) {
+	if len(charset) == 0 || len(charset) > 256 {
+		return "", ErrInvalidCharset
+	}
+
+	// bytes above the largest multiple of charset length are rejected, so all characters are equally likely
+	limit := 256 - 256%len(charset)
 
-	b := make([]rune, size)
-	for i := range b {
-		b[i] = chars[rnd.Intn(len(chars))]
+	b := make([]byte, size)
+	buf := make([]byte, size)
+	for i := 0; i < size; {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, r := range buf {
+			if int(r) >= limit {
+				continue
+			}
+			b[i] = charset[int(r)%len(charset)]
+			i++
+			if i == size {
+				break
+			}
+		}
 	}
 
-	return string(b)
+	return string(b), nil
 }
@@ -0,0 +1,57 @@
+package random
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		charset string
+		wantErr error
+	}{
+		{name: "Alphanumeric", size: 6, charset: Alphanumeric},
+		{name: "Base62", size: 20, charset: Base62},
+		{name: "Single character", size: 4, charset: "a"},
+		{name: "Empty size", size: 0, charset: Base62},
+		{name: "Empty charset", size: 6, charset: "", wantErr: ErrInvalidCharset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateFrom(tt.size, tt.charset)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateFrom() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != tt.size {
+				t.Errorf("GenerateFrom() = %q, want length %d", got, tt.size)
+			}
+			for _, c := range got {
+				if !strings.ContainsRune(tt.charset, c) {
+					t.Errorf("GenerateFrom() = %q, contains %q out of charset", got, c)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCoversCharset(t *testing.T) {
+	seen := make(map[rune]bool)
+	for i := 0; i < 200; i++ {
+		for _, c := range Generate(20) {
+			seen[c] = true
+		}
+	}
+
+	for _, c := range Alphanumeric {
+		if !seen[c] {
+			t.Errorf("Generate() never returned %q", c)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package timeseries
+
+import (
+	"errors"
+	"time"
+)
+
+type Interval string
+
+const (
+	Hour Interval = "hour"
+	Day  Interval = "day"
+	Week Interval = "week"
+)
+
+var ErrInvalidInterval = errors.New("interval must be one of: hour, day, week")
+
+// Point is a value of the bucket, which starts at Time.
+type Point struct {
+	Time  time.Time
+	Value int
+}
+
+// ParseInterval parses an interval name. Empty name is parsed as Day.
+func ParseInterval(s string) (Interval, error) {
+	switch Interval(s) {
+	case "":
+		return Day, nil
+	case Hour, Day, Week:
+		return Interval(s), nil
+	default:
+		return "", ErrInvalidInterval
+	}
+}
+
+// Truncate returns the start of the bucket containing t, in location loc.
+// Weeks start on Monday, as in PostgreSQL date_trunc.
+func (i Interval) Truncate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+
+	switch i {
+	case Hour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case Week:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// Next returns the start of the bucket following the bucket, which starts at t.
+// Days and weeks follow calendar of t location, so they may be shorter or longer than 24 hours on DST changes.
+func (i Interval) Next(t time.Time) time.Time {
+	switch i {
+	case Hour:
+		return t.Add(time.Hour)
+	case Week:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Buckets returns starts of all buckets between from and to, both inclusive, in location loc.
+// If there are more than limit buckets, the function returns nil and false.
+func Buckets(from time.Time, to time.Time, interval Interval, loc *time.Location, limit int) ([]time.Time, bool) {
+	var buckets []time.Time
+
+	for t := interval.Truncate(from, loc); !t.After(to); t = interval.Next(t) {
+		if len(buckets) == limit {
+			return nil, false
+		}
+		buckets = append(buckets, t)
+	}
+
+	return buckets, true
+}
+
+// Fill returns a point for every bucket, taking values from given points and zero for buckets without any.
+// Points are matched with buckets by the same instant.
+func Fill(buckets []time.Time, points []Point) []Point {
+	values := make(map[int64]int, len(points))
+	for _, p := range points {
+		values[p.Time.Unix()] += p.Value
+	}
+
+	filled := make([]Point, len(buckets))
+	for i, bucket := range buckets {
+		filled[i] = Point{
+			Time:  bucket,
+			Value: values[bucket.Unix()],
+		}
+	}
+
+	return filled
+}
+
+// WallClock interprets the wall clock of t (e.g. a timestamp without time zone from database) as a time in location loc.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
@@ -0,0 +1,147 @@
+package timeseries
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("can't load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestInterval_Truncate(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name     string
+		interval Interval
+		t        time.Time
+		loc      *time.Location
+		want     time.Time
+	}{
+		{
+			name:     "Hour in UTC",
+			interval: Hour,
+			t:        time.Date(2023, 10, 11, 14, 35, 12, 0, time.UTC),
+			loc:      time.UTC,
+			want:     time.Date(2023, 10, 11, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Day in another time zone",
+			interval: Day,
+			t:        time.Date(2023, 10, 11, 23, 30, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2023, 10, 12, 0, 0, 0, 0, berlin),
+		},
+		{
+			name:     "Week starts on Monday",
+			interval: Week,
+			t:        time.Date(2023, 10, 15, 12, 0, 0, 0, time.UTC), // Sunday
+			loc:      time.UTC,
+			want:     time.Date(2023, 10, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Monday is the start of its week",
+			interval: Week,
+			t:        time.Date(2023, 10, 9, 12, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			want:     time.Date(2023, 10, 9, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.interval.Truncate(tt.t, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("Truncate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval Interval
+		loc      *time.Location
+		limit    int
+		want     []time.Time
+		wantOk   bool
+	}{
+		{
+			name:     "Days including partial ones",
+			from:     time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 3, 1, 0, 0, 0, time.UTC),
+			interval: Day,
+			loc:      time.UTC,
+			limit:    10,
+			want: []time.Time{
+				time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC),
+			},
+			wantOk: true,
+		},
+		{
+			name:     "Days over DST change",
+			from:     time.Date(2023, 10, 28, 12, 0, 0, 0, berlin),
+			to:       time.Date(2023, 10, 30, 12, 0, 0, 0, berlin),
+			interval: Day,
+			loc:      berlin,
+			limit:    10,
+			want: []time.Time{
+				time.Date(2023, 10, 28, 0, 0, 0, 0, berlin),
+				time.Date(2023, 10, 29, 0, 0, 0, 0, berlin),
+				time.Date(2023, 10, 30, 0, 0, 0, 0, berlin),
+			},
+			wantOk: true,
+		},
+		{
+			name:     "Too many buckets",
+			from:     time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+			interval: Hour,
+			loc:      time.UTC,
+			limit:    10,
+			want:     nil,
+			wantOk:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Buckets(tt.from, tt.to, tt.interval, tt.loc, tt.limit)
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Buckets() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestFill(t *testing.T) {
+	buckets := []time.Time{
+		time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC),
+	}
+	points := []Point{
+		{Time: time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC), Value: 5},
+	}
+
+	want := []Point{
+		{Time: buckets[0], Value: 0},
+		{Time: buckets[1], Value: 5},
+		{Time: buckets[2], Value: 0},
+	}
+
+	if got := Fill(buckets, points); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fill() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package useragent
+
+import "strings"
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+
+	Unknown = "unknown"
+)
+
+// Agent contains client information parsed from a User-Agent header.
+type Agent struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// token is a substring of User-Agent header, that identifies some value.
+type token struct {
+	substr string
+	value  string
+}
+
+// Browsers are checked in order, because most of them mention each other (e.g. every Chromium based browser contains "Chrome" and "Safari").
+var browsers = []token{
+	{"edg/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"yabrowser/", "Yandex"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"chromium/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"curl/", "curl"},
+}
+
+var systems = []token{
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"cros", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+var bots = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "preview"}
+
+// Parse parses User-Agent header into Agent.
+// Values which can't be recognized are set to Unknown.
+func Parse(ua string) Agent {
+	ua = strings.ToLower(ua)
+
+	agent := Agent{
+		Browser: match(ua, browsers),
+		OS:      match(ua, systems),
+		Device:  DeviceDesktop,
+	}
+
+	switch {
+	case ua == "":
+		agent.Device = Unknown
+	case containsAny(ua, bots):
+		agent.Device = DeviceBot
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		agent.Device = DeviceTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "ipod"):
+		agent.Device = DeviceMobile
+	}
+
+	return agent
+}
+
+// match returns the value of the first token found in s, or Unknown.
+func match(s string, tokens []token) string {
+	for _, t := range tokens {
+		if strings.Contains(s, t.substr) {
+			return t.value
+		}
+	}
+	return Unknown
+}
+
+// containsAny reports whether any of substrings is within s.
+func containsAny(s string, substrings []string) bool {
+	for _, substr := range substrings {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalOS returns the name of OS as it's parsed, matching name ignoring case, e.g. "ios" is "iOS".
+func CanonicalOS(name string) (string, bool) {
+	return canonical(name, systems)
+}
+
+// CanonicalBrowser returns the name of browser as it's parsed, matching name ignoring case.
+func CanonicalBrowser(name string) (string, bool) {
+	return canonical(name, browsers)
+}
+
+// CanonicalDevice returns the type of device as it's parsed, matching name ignoring case.
+func CanonicalDevice(name string) (string, bool) {
+	for _, device := range []string{DeviceDesktop, DeviceMobile, DeviceTablet, DeviceBot} {
+		if strings.EqualFold(name, device) {
+			return device, true
+		}
+	}
+	return "", false
+}
+
+// canonical returns the value of tokens equal to name ignoring case.
+func canonical(name string, tokens []token) (string, bool) {
+	for _, t := range tokens {
+		if strings.EqualFold(name, t.value) {
+			return t.value, true
+		}
+	}
+	return "", false
+}
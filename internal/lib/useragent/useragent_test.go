@@ -0,0 +1,64 @@
+package useragent
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want Agent
+	}{
+		{
+			name: "Chrome on Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: Agent{Browser: "Chrome", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Edge on Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			want: Agent{Browser: "Edge", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Safari on iPhone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			want: Agent{Browser: "Safari", OS: "iOS", Device: DeviceMobile},
+		},
+		{
+			name: "Safari on iPad",
+			ua:   "Mozilla/5.0 (iPad; CPU OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			want: Agent{Browser: "Safari", OS: "iOS", Device: DeviceTablet},
+		},
+		{
+			name: "Chrome on Android phone",
+			ua:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			want: Agent{Browser: "Chrome", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name: "Android tablet",
+			ua:   "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: Agent{Browser: "Chrome", OS: "Android", Device: DeviceTablet},
+		},
+		{
+			name: "Firefox on macOS",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 14.1; rv:120.0) Gecko/20100101 Firefox/120.0",
+			want: Agent{Browser: "Firefox", OS: "macOS", Device: DeviceDesktop},
+		},
+		{
+			name: "Googlebot",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: Agent{Browser: Unknown, OS: Unknown, Device: DeviceBot},
+		},
+		{
+			name: "Empty user agent",
+			ua:   "",
+			want: Agent{Browser: Unknown, OS: Unknown, Device: Unknown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.ua); got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,21 +6,33 @@ import (
 	"backend/internal/lib/logger/prettyslog"
 	"backend/internal/lib/logger/sl"
 	"backend/internal/service"
+	"backend/internal/service/alias"
+	"backend/internal/service/counter"
+	"backend/internal/service/destination"
+	"backend/internal/service/domain"
+	"backend/internal/service/geoip"
 	"backend/internal/service/hash"
+	"backend/internal/service/qr"
 	"backend/internal/service/repository"
 	"backend/internal/service/repository/postgres"
 	"backend/internal/service/repository/redis"
 	"backend/internal/service/token"
 	"context"
 	"errors"
+	"expvar"
 	"github.com/gin-gonic/gin"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// counterDrainTimeout is the maximum duration of flushing buffered clicks and redirects counters on shutdown.
+const counterDrainTimeout = 30 * time.Second
+
 // App is a main app struct.
 type App struct {
 	config *config.Config
@@ -59,8 +71,47 @@ func (a *App) Run() {
 		os.Exit(1)
 	}
 
+	geoIP, err := geoip.Open(a.config.GeoIP, a.log)
+	if err != nil {
+		a.log.Warn("error occurred while opening geoip database, countries won't be resolved until it's loaded", sl.Err(err))
+	}
+	geoIP.Start()
+
 	repo := repository.New(postgresDB, redisDB, a.config)
-	srv := service.New(tokenManager, a.hasher, repo)
+
+	redirectCounter := counter.New(repo.Url, repo.Click, a.config.RedirectCounter, a.log)
+	redirectCounter.Start()
+	expvar.Publish("redirect_counter", expvar.Func(func() any {
+		return redirectCounter.Stats()
+	}))
+
+	domainVerifier := domain.NewVerifier(net.DefaultResolver)
+
+	qrLogo, err := qr.LoadLogo(a.config.QR.LogoPath)
+	if err != nil {
+		a.log.Warn("error occurred while loading qr logo, qr codes with logo won't be available", sl.Err(err))
+	}
+	qrGenerator := qr.New(qrLogo)
+
+	aliasAllocator, err := alias.New(a.config.Alias, repo.Url, a.log)
+	if err != nil {
+		a.log.Error("error occurred while creating alias generator", sl.Err(err))
+		os.Exit(1)
+	}
+
+	aliasPolicy, err := alias.NewPolicy(a.config.Alias.Policy)
+	if err != nil {
+		a.log.Error("error occurred while creating alias policy", sl.Err(err))
+		os.Exit(1)
+	}
+
+	destinationPolicy, err := destination.New(a.config.Destination, a.config.BaseURL, repo.Domain, net.DefaultResolver)
+	if err != nil {
+		a.log.Error("error occurred while creating destination policy", sl.Err(err))
+		os.Exit(1)
+	}
+
+	srv := service.New(tokenManager, a.hasher, repo, geoIP, redirectCounter, domainVerifier, qrGenerator, aliasAllocator, aliasPolicy, destinationPolicy)
 	r := router.New(a.config, a.log, srv)
 
 	server := &http.Server{
@@ -81,6 +132,27 @@ func (a *App) Run() {
 
 	a.log.Info("server started", slog.String("address", server.Addr))
 
+	// metrics expose process internals, so they're served only by a separate internal listener
+	var debugServer *http.Server
+	if a.config.Server.DebugAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler()) // runtime and redirects counter metrics
+		debugServer = &http.Server{
+			Addr:        a.config.Server.DebugAddress,
+			Handler:     mux,
+			ReadTimeout: a.config.Server.Timeout,
+			IdleTimeout: a.config.Server.IdleTimeout,
+		}
+
+		go func() {
+			if err := debugServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				a.log.Error("failed to start debug server", sl.Err(err))
+			}
+		}()
+
+		a.log.Info("debug server started", slog.String("address", debugServer.Addr))
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
@@ -92,8 +164,29 @@ func (a *App) Run() {
 		a.log.Error("error occurred on server shutting down", sl.Err(err))
 	}
 
+	if debugServer != nil {
+		if err = debugServer.Shutdown(context.Background()); err != nil {
+			a.log.Error("error occurred on debug server shutting down", sl.Err(err))
+		}
+	}
+
 	a.log.Info("server stopped")
 
+	ctx, cancel := context.WithTimeout(context.Background(), counterDrainTimeout)
+	err = redirectCounter.Close(ctx)
+	cancel()
+	if err != nil {
+		a.log.Error("error occurred on redirects counter draining", sl.Err(err))
+	}
+
+	stats := redirectCounter.Stats()
+	a.log.Info("redirects counter drained",
+		slog.Int64("flushed", stats.Flushed),
+		slog.Int64("dropped", stats.Dropped),
+		slog.Int64("failed", stats.Failed),
+		slog.Int64("clicks_failed", stats.ClicksFailed),
+	)
+
 	err = redisDB.Close()
 	if err != nil {
 		a.log.Error("error occurred on redis connection closing down", sl.Err(err))
@@ -107,6 +200,11 @@ func (a *App) Run() {
 	}
 
 	a.log.Info("postgres connection closed")
+
+	err = geoIP.Close()
+	if err != nil {
+		a.log.Error("error occurred on geoip database closing", sl.Err(err))
+	}
 }
 
 func initLogger(env string) *slog.Logger {
@@ -0,0 +1,158 @@
+package alias
+
+import (
+	"backend/internal/config"
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Names of generators in config.
+const (
+	GeneratorBase62   = "base62"
+	GeneratorSequence = "sequence"
+	GeneratorWords    = "words"
+)
+
+const (
+	// MaxLength is the maximum length of alias which can be stored in database.
+	MaxLength = 20
+	// growAfter is the number of consecutive collisions, after which the keyspace is considered crowded.
+	growAfter = 3
+)
+
+var (
+	ErrUnknownGenerator = errors.New("alias: unknown generator")
+	ErrInvalidLength    = errors.New("alias: length must be positive and not greater than max length")
+	ErrNoAliasAvailable = errors.New("alias: can't generate unique alias")
+)
+
+// Generator generates aliases of urls.
+type Generator interface {
+	// Generate returns a new alias of given length. Generators, which can't produce exact lengths,
+	// use the length as a measure of keyspace size: longer lengths give more aliases.
+	Generate(ctx context.Context, length int) (string, error)
+}
+
+// Allocator generates unique aliases. Collisions with existing aliases are retried with new aliases,
+// and generated aliases are lengthened once collisions show, that the keyspace at the current length is crowded.
+type Allocator struct {
+	generator   Generator
+	log         *slog.Logger
+	maxLength   int
+	maxAttempts int
+
+	length     atomic.Int64
+	collisions atomic.Int64 // consecutive collisions at the current length
+}
+
+// New returns a new instance of *Allocator with the generator chosen in config.
+// Sequence is used only by the sequence generator.
+func New(cfg config.Alias, sequence Sequence, log *slog.Logger) (*Allocator, error) {
+	var (
+		generator Generator
+		maxLength = MaxLength
+	)
+
+	switch cfg.Generator {
+	case GeneratorBase62:
+		generator = NewBase62()
+	case GeneratorSequence:
+		generator = NewSequence(sequence, cfg.Salt)
+	case GeneratorWords:
+		generator = NewWords()
+		maxLength = MaxWordsLength
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGenerator, cfg.Generator)
+	}
+
+	if cfg.MaxLength > maxLength || cfg.Length < 1 || cfg.Length > cfg.MaxLength {
+		return nil, ErrInvalidLength
+	}
+
+	return NewAllocator(generator, cfg.Length, cfg.MaxLength, cfg.MaxAttempts, log), nil
+}
+
+// NewAllocator returns a new instance of *Allocator, which generates aliases of given length
+// by the generator and lengthens them up to maxLength. Every alias is tried at most maxAttempts times.
+func NewAllocator(generator Generator, length int, maxLength int, maxAttempts int, log *slog.Logger) *Allocator {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	a := &Allocator{
+		generator:   generator,
+		log:         log.With(slog.String("op", "alias.Allocator")),
+		maxLength:   maxLength,
+		maxAttempts: maxAttempts,
+	}
+	a.length.Store(int64(length))
+	return a
+}
+
+// Length returns the current length of generated aliases.
+func (a *Allocator) Length() int {
+	return int(a.length.Load())
+}
+
+// MaxAttempts returns the number of attempts to create an url with a generated alias.
+func (a *Allocator) MaxAttempts() int {
+	return a.maxAttempts
+}
+
+// Generate returns a new alias of the current length. The alias isn't checked for uniqueness,
+// so report the result of saving it with Observe.
+func (a *Allocator) Generate(ctx context.Context) (string, error) {
+	return a.generator.Generate(ctx, a.Length())
+}
+
+// Observe records the result of saving an url with a generated alias.
+// When saves collide with existing aliases several times in a row, the length of aliases is increased.
+func (a *Allocator) Observe(err error) {
+	if !repoUrl.IsErrShortUrlAlreadyExists(err) {
+		a.collisions.Store(0)
+		return
+	}
+
+	if a.collisions.Add(1) < growAfter {
+		return
+	}
+	a.collisions.Store(0)
+
+	length := a.length.Load()
+	if length < int64(a.maxLength) && a.length.CompareAndSwap(length, length+1) {
+		a.log.Info("alias length increased, because aliases collide too often",
+			slog.Int64("length", length+1),
+		)
+	}
+}
+
+// Allocate generates aliases and saves them by create until an alias doesn't collide with existing ones.
+// Create must return an ErrShortUrlAlreadyExists of url repository on collisions, other errors are returned as is.
+// If all attempts collide, the function will return an ErrNoAliasAvailable.
+func (a *Allocator) Allocate(ctx context.Context, create func(alias string) error) (string, error) {
+	for attempt := 0; attempt < a.maxAttempts; attempt++ {
+		alias, err := a.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		err = create(alias)
+		a.Observe(err)
+		if repoUrl.IsErrShortUrlAlreadyExists(err) {
+			continue
+		}
+
+		return alias, err
+	}
+
+	a.log.Warn("all attempts to generate unique alias collided",
+		slog.Int("attempts", a.maxAttempts),
+		slog.Int("length", a.Length()),
+	)
+
+	return "", ErrNoAliasAvailable
+}
@@ -0,0 +1,179 @@
+package alias
+
+import (
+	repoUrl "backend/internal/service/repository/postgres/url"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+type counterSequence struct {
+	n int64
+}
+
+func (s *counterSequence) NextAliasNumber(context.Context) (int64, error) {
+	s.n++
+	return s.n, nil
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator Generator
+		length    int
+		pattern   string
+	}{
+		{name: "Base62", generator: NewBase62(), length: 6, pattern: `^[0-9a-zA-Z]{6}$`},
+		{name: "Sequence", generator: NewSequence(&counterSequence{}, "salt"), length: 6, pattern: `^[0-9a-zA-Z]{6}$`},
+		{name: "Words", generator: NewWords(), length: 6, pattern: `^[a-z]+-[a-z]+-[0-9]{2}$`},
+		{name: "Short words", generator: NewWords(), length: 1, pattern: `^[a-z]+-[a-z]+-[0-9]$`},
+		{name: "Longest words", generator: NewWords(), length: MaxWordsLength, pattern: `^[a-z]+-[a-z]+-[0-9]{8}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			for i := 0; i < 100; i++ {
+				got, err := tt.generator.Generate(context.Background(), tt.length)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if !re.MatchString(got) || len(got) > MaxLength {
+					t.Fatalf("Generate() = %q, want match of %s", got, tt.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestSequenceEncodeIsUnique(t *testing.T) {
+	g := NewSequence(&counterSequence{}, "salt")
+
+	// the whole keyspace of length 2 and numbers beyond it
+	seen := make(map[string]int64)
+	for n := int64(0); n < 62*62+100; n++ {
+		alias := g.Encode(n, 2)
+		if prev, ok := seen[alias]; ok {
+			t.Fatalf("Encode(%d) = Encode(%d) = %q", n, prev, alias)
+		}
+		seen[alias] = n
+	}
+
+	if a, b := g.Encode(1, 6), g.Encode(2, 6); a[:4] == b[:4] {
+		t.Errorf("Encode() of consecutive numbers %q and %q aren't scrambled", a, b)
+	}
+	if a, b := g.Encode(1, 6), NewSequence(&counterSequence{}, "other").Encode(1, 6); a == b {
+		t.Errorf("Encode() with different salts = %q", a)
+	}
+}
+
+// fixedGenerator returns aliases from the list in order.
+type fixedGenerator struct {
+	aliases []string
+	lengths []int
+}
+
+func (g *fixedGenerator) Generate(_ context.Context, length int) (string, error) {
+	g.lengths = append(g.lengths, length)
+	alias := g.aliases[0]
+	g.aliases = g.aliases[1:]
+	return alias, nil
+}
+
+func TestAllocatorAllocate(t *testing.T) {
+	errDatabase := errors.New("database is down")
+	existing := map[string]bool{"a": true, "b": true, "c": true, "d": true}
+
+	tests := []struct {
+		name        string
+		aliases     []string
+		createErr   error
+		maxAttempts int
+		want        string
+		wantErr     error
+		wantLengths []int
+	}{
+		{
+			name:        "First alias is free",
+			aliases:     []string{"x"},
+			maxAttempts: 3,
+			want:        "x",
+			wantLengths: []int{6},
+		},
+		{
+			name:        "Collisions are retried",
+			aliases:     []string{"a", "b", "x"},
+			maxAttempts: 3,
+			want:        "x",
+			wantLengths: []int{6, 6, 6},
+		},
+		{
+			name:        "Length grows when keyspace is crowded",
+			aliases:     []string{"a", "b", "c", "x"},
+			maxAttempts: 5,
+			want:        "x",
+			wantLengths: []int{6, 6, 6, 7},
+		},
+		{
+			name:        "All attempts collide",
+			aliases:     []string{"a", "b"},
+			maxAttempts: 2,
+			wantErr:     ErrNoAliasAvailable,
+			wantLengths: []int{6, 6},
+		},
+		{
+			name:        "Other errors aren't retried",
+			aliases:     []string{"x"},
+			createErr:   errDatabase,
+			maxAttempts: 3,
+			wantErr:     errDatabase,
+			wantLengths: []int{6},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &fixedGenerator{aliases: tt.aliases}
+			a := NewAllocator(g, 6, 7, tt.maxAttempts, log)
+
+			got, err := a.Allocate(context.Background(), func(alias string) error {
+				if existing[alias] {
+					return repoUrl.ErrShortUrlAlreadyExists
+				}
+				return tt.createErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Allocate() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want && tt.wantErr == nil {
+				t.Errorf("Allocate() = %q, want %q", got, tt.want)
+			}
+			if len(g.lengths) != len(tt.wantLengths) {
+				t.Fatalf("Allocate() generated lengths %v, want %v", g.lengths, tt.wantLengths)
+			}
+			for i := range g.lengths {
+				if g.lengths[i] != tt.wantLengths[i] {
+					t.Fatalf("Allocate() generated lengths %v, want %v", g.lengths, tt.wantLengths)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocatorLengthIsLimited(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a := NewAllocator(NewBase62(), 6, 7, 1, log)
+
+	for i := 0; i < 10*growAfter; i++ {
+		a.Observe(repoUrl.ErrShortUrlAlreadyExists)
+	}
+
+	if got := a.Length(); got != 7 {
+		t.Errorf("Length() = %d, want 7", got)
+	}
+}
@@ -0,0 +1,19 @@
+package alias
+
+import (
+	"backend/internal/lib/random"
+	"context"
+)
+
+// Base62 generates aliases of random latin letters of both cases and digits read from crypto/rand.
+type Base62 struct{}
+
+// NewBase62 returns a new instance of *Base62.
+func NewBase62() *Base62 {
+	return &Base62{}
+}
+
+// Generate returns a random alias of given length.
+func (g *Base62) Generate(_ context.Context, length int) (string, error) {
+	return random.GenerateFrom(length, random.Base62)
+}
@@ -0,0 +1,141 @@
+package alias
+
+import (
+	"backend/internal/config"
+	"backend/internal/lib/random"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// urlSafe are characters, which can be allowed in custom aliases: they're kept as is in paths of short urls
+// and don't clash with suffixes of redirect routes, .qr of QR codes and + of previews.
+const urlSafe = random.Base62 + "-_~"
+
+// separators are ignored when aliases are matched against blocklist, so words can't be split by them.
+var separators = strings.NewReplacer("-", "", "_", "", "~", "")
+
+// reservedWords are names of routes, current and possible top-level ones, which can't be custom aliases.
+var reservedWords = []string{
+	"s", "api", "debug", "docs", "swagger", "static", "assets", "health", "metrics",
+	"auth", "login", "logout", "signup", "register", "admin", "user", "url", "domain", "tag", "folder",
+}
+
+var (
+	ErrInvalidCharset     = errors.New("alias: charset must consist of letters, digits, '-', '_' and '~'")
+	ErrInvalidLengthRange = errors.New("alias: min length must be positive and not greater than max length")
+
+	ErrAliasTooShort = errors.New("alias is too short")
+	ErrAliasTooLong  = errors.New("alias is too long")
+	ErrAliasCharset  = errors.New("alias contains not allowed character")
+	ErrAliasReserved = errors.New("alias is reserved")
+	ErrAliasBlocked  = errors.New("alias contains a blocked word")
+)
+
+// Policy checks custom aliases chosen by users. Reserved words and blocklist are matched case-insensitively.
+type Policy struct {
+	charset   string
+	minLength int
+	maxLength int
+	reserved  map[string]struct{}
+	blocklist []string
+	foldCase  bool
+}
+
+// NewPolicy returns a new instance of *Policy configured by cfg. The blocklist file is read once.
+func NewPolicy(cfg config.AliasPolicy) (*Policy, error) {
+	if cfg.Charset == "" || strings.Trim(cfg.Charset, urlSafe) != "" {
+		return nil, ErrInvalidCharset
+	}
+	if cfg.MinLength < 1 || cfg.MinLength > cfg.MaxLength || cfg.MaxLength > MaxLength {
+		return nil, ErrInvalidLengthRange
+	}
+
+	reserved := make(map[string]struct{}, len(reservedWords)+len(cfg.Reserved))
+	for _, word := range append(reservedWords, cfg.Reserved...) {
+		reserved[strings.ToLower(word)] = struct{}{}
+	}
+
+	var blocklist []string
+	if cfg.BlocklistPath != "" {
+		var err error
+		if blocklist, err = readBlocklist(cfg.BlocklistPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Policy{
+		charset:   cfg.Charset,
+		minLength: cfg.MinLength,
+		maxLength: cfg.MaxLength,
+		reserved:  reserved,
+		blocklist: blocklist,
+		foldCase:  cfg.FoldCase,
+	}, nil
+}
+
+// Normalize returns the alias as it's stored, lowercased if the policy folds case.
+// If the alias breaks a rule of the policy, the function returns an error describing the rule,
+// which wraps one of ErrAlias* errors.
+func (p *Policy) Normalize(alias string) (string, error) {
+	if p.foldCase {
+		alias = strings.ToLower(alias)
+	}
+
+	length := utf8.RuneCountInString(alias)
+	if length < p.minLength {
+		return "", fmt.Errorf("%w, it must be at least %d characters long", ErrAliasTooShort, p.minLength)
+	}
+	if length > p.maxLength {
+		return "", fmt.Errorf("%w, it must be at most %d characters long", ErrAliasTooLong, p.maxLength)
+	}
+
+	for _, r := range alias {
+		if !strings.ContainsRune(p.charset, r) {
+			return "", fmt.Errorf("%w %q", ErrAliasCharset, r)
+		}
+	}
+
+	key := strings.ToLower(alias)
+	if _, ok := p.reserved[key]; ok {
+		return "", ErrAliasReserved
+	}
+
+	key = separators.Replace(key)
+	for _, word := range p.blocklist {
+		if strings.Contains(key, word) {
+			return "", ErrAliasBlocked
+		}
+	}
+
+	return alias, nil
+}
+
+// readBlocklist reads words of blocklist file: a word per line, empty lines and lines starting with # are skipped.
+func readBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("alias: can't open blocklist: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if word := separators.Replace(strings.ToLower(line)); word != "" {
+			words = append(words, word)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("alias: can't read blocklist: %w", err)
+	}
+
+	return words, nil
+}
@@ -0,0 +1,176 @@
+package alias
+
+import (
+	"backend/internal/config"
+	"errors"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testPolicyConfig(t *testing.T) config.AliasPolicy {
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("# brands\nAcme\n\nbad-word\n-\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return config.AliasPolicy{
+		Charset:       urlSafe,
+		MinLength:     3,
+		MaxLength:     10,
+		Reserved:      []string{"Pricing"},
+		BlocklistPath: blocklist,
+	}
+}
+
+func TestPolicyNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		alias    string
+		foldCase bool
+		want     string
+		wantErr  error
+	}{
+		{name: "Valid alias", alias: "My-Link_1", want: "My-Link_1"},
+		{name: "Folded case", alias: "My-Link", foldCase: true, want: "my-link"},
+		{name: "Too short", alias: "ab", wantErr: ErrAliasTooShort},
+		{name: "Too long", alias: "abcdefghijk", wantErr: ErrAliasTooLong},
+		{name: "Not ASCII letters", alias: "ééé", wantErr: ErrAliasCharset},
+		{name: "Dot of QR suffix", alias: "link.qr", wantErr: ErrAliasCharset},
+		{name: "Plus of preview suffix", alias: "link+", wantErr: ErrAliasCharset},
+		{name: "Slash", alias: "a/b/c", wantErr: ErrAliasCharset},
+		{name: "Route name", alias: "API", wantErr: ErrAliasReserved},
+		{name: "Configured reserved word", alias: "pricing", wantErr: ErrAliasReserved},
+		{name: "Blocked word inside", alias: "myACMEshop", wantErr: ErrAliasBlocked},
+		{name: "Blocked word split by separators", alias: "bad_w-ord", wantErr: ErrAliasBlocked},
+		{name: "Blocked word is matched without separators", alias: "badword", wantErr: ErrAliasBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPolicyConfig(t)
+			cfg.FoldCase = tt.foldCase
+
+			p, err := NewPolicy(cfg)
+			if err != nil {
+				t.Fatalf("NewPolicy() error = %v", err)
+			}
+
+			got, err := p.Normalize(tt.alias)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.AliasPolicy)
+		wantErr error
+	}{
+		{name: "Valid config", modify: func(cfg *config.AliasPolicy) {}},
+		{name: "Dot in charset", modify: func(cfg *config.AliasPolicy) { cfg.Charset += "." }, wantErr: ErrInvalidCharset},
+		{name: "Empty charset", modify: func(cfg *config.AliasPolicy) { cfg.Charset = "" }, wantErr: ErrInvalidCharset},
+		{name: "Zero min length", modify: func(cfg *config.AliasPolicy) { cfg.MinLength = 0 }, wantErr: ErrInvalidLengthRange},
+		{name: "Min length over max", modify: func(cfg *config.AliasPolicy) { cfg.MinLength = 11 }, wantErr: ErrInvalidLengthRange},
+		{name: "Max length over column", modify: func(cfg *config.AliasPolicy) { cfg.MaxLength = MaxLength + 1 }, wantErr: ErrInvalidLengthRange},
+		{name: "Missing blocklist", modify: func(cfg *config.AliasPolicy) { cfg.BlocklistPath += ".missing" }, wantErr: os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPolicyConfig(t)
+			tt.modify(&cfg)
+
+			_, err := NewPolicy(cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewPolicy() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicySuggest(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		charset     string
+		want        []string
+	}{
+		{
+			name:        "Domain and path",
+			destination: "https://www.github.com/makeshort/backend",
+			want: []string{
+				"backend", "makeshort-backend", "github-backend", "github",
+				"backend-2", "makeshort-backend-2", "github-backend-2", "github-2",
+				"backend-3", "makeshort-backend-3", "github-backend-3", "github-3",
+				"backend-4", "makeshort-backend-4", "github-backend-4", "github-4",
+				"backend-5", "makeshort-backend-5", "github-backend-5", "github-5",
+			},
+		},
+		{
+			name:        "Too long words are skipped",
+			destination: "https://example.com/a-very-long-article-title",
+			want:        []string{"example", "example-2", "example-3", "example-4", "example-5"},
+		},
+		{
+			name:        "Only domain",
+			destination: "https://example.com/",
+			want:        []string{"example", "example-2", "example-3", "example-4", "example-5"},
+		},
+		{
+			name:        "Extensions, numbers and escapes",
+			destination: "https://blog.acme.io/2024/My%20Post.html",
+			want:        []string{"my-post", "my-post-2", "my-post-3", "my-post-4", "my-post-5"},
+		},
+		{
+			name:        "Reserved words are skipped",
+			destination: "https://example.com/api",
+			want: []string{
+				"example-api", "example",
+				"api-2", "example-api-2", "example-2",
+				"api-3", "example-api-3", "example-3",
+				"api-4", "example-api-4", "example-4",
+				"api-5", "example-api-5", "example-5",
+			},
+		},
+		{
+			name:        "Charset without separators",
+			destination: "https://example.com/a-b",
+			charset:     "abcdefghijklmnopqrstuvwxyz0123456789",
+			want:        []string{"ab", "exampleab", "example", "ab2", "exampleab2", "example2", "ab3", "exampleab3", "example3", "ab4", "exampleab4", "example4", "ab5", "exampleab5", "example5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPolicyConfig(t)
+			cfg.MinLength = 2
+			cfg.MaxLength = 20
+			if tt.charset != "" {
+				cfg.Charset = tt.charset
+			}
+
+			p, err := NewPolicy(cfg)
+			if err != nil {
+				t.Fatalf("NewPolicy() error = %v", err)
+			}
+
+			destination, err := neturl.Parse(tt.destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := p.Suggest(destination); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package alias
+
+import (
+	"backend/internal/lib/random"
+	"context"
+	"errors"
+	"math/big"
+)
+
+// sequenceMultiplier scrambles sequence numbers, so consecutive aliases don't look consecutive.
+// It's a prime, which doesn't divide the powers of 62, so multiplication by it is a bijection modulo them.
+const sequenceMultiplier = 2147483647
+
+var ErrInvalidSequence = errors.New("alias: sequence returned a negative number")
+
+// Sequence returns unique increasing numbers, e.g. from a database sequence.
+type Sequence interface {
+	NextAliasNumber(ctx context.Context) (int64, error)
+}
+
+// SequenceGenerator generates aliases by encoding numbers of a sequence in the hashids way:
+// numbers are scrambled and written by digits of the base62 alphabet shuffled by salt.
+// Different numbers always get different aliases of the same length, so aliases collide only with custom ones.
+type SequenceGenerator struct {
+	sequence Sequence
+	alphabet string
+}
+
+// NewSequence returns a new instance of *SequenceGenerator, the salt makes its aliases unpredictable.
+func NewSequence(sequence Sequence, salt string) *SequenceGenerator {
+	return &SequenceGenerator{
+		sequence: sequence,
+		alphabet: shuffle(random.Base62, salt),
+	}
+}
+
+// Generate returns an alias of the next number of the sequence.
+// The alias is at least given length, numbers beyond the keyspace of the length get longer aliases.
+func (g *SequenceGenerator) Generate(ctx context.Context, length int) (string, error) {
+	n, err := g.sequence.NextAliasNumber(ctx)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", ErrInvalidSequence
+	}
+
+	return g.Encode(n, length), nil
+}
+
+// Encode returns the alias of the number given length.
+func (g *SequenceGenerator) Encode(n int64, length int) string {
+	base := big.NewInt(int64(len(g.alphabet)))
+	keyspace := new(big.Int).Exp(base, big.NewInt(int64(length)), nil)
+
+	x := big.NewInt(n)
+	if x.Cmp(keyspace) < 0 {
+		x.Mul(x, big.NewInt(sequenceMultiplier)).Mod(x, keyspace)
+	}
+
+	var digits []byte
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		digits = append(digits, g.alphabet[mod.Int64()])
+	}
+	for len(digits) < length {
+		digits = append(digits, g.alphabet[0])
+	}
+
+	// digits are collected from the least significant one
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+
+	return string(digits)
+}
+
+// shuffle deterministically shuffles the alphabet by salt, as hashids does.
+func shuffle(alphabet string, salt string) string {
+	b := []byte(alphabet)
+	if salt == "" {
+		return alphabet
+	}
+
+	for i, v, p := len(b)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,127 @@
+package alias
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxSuggestionWords is the number of last path segments of destination, which suggestions are made of.
+	maxSuggestionWords = 2
+	// maxSuggestionNumber is the greatest number appended to suggestions to make them available.
+	maxSuggestionNumber = 5
+)
+
+// Suggest returns human-friendly aliases derived from the domain and the path of destination,
+// which comply with the policy, most preferred first. Suggestions aren't checked for availability.
+func (p *Policy) Suggest(destination *url.URL) []string {
+	sep := p.separator()
+
+	var words []string
+	for _, segment := range strings.Split(destination.Path, "/") {
+		segment = strings.TrimSuffix(segment, path.Ext(segment))
+		if word := slug(segment, sep); hasLetter(word) {
+			words = append(words, word)
+		}
+	}
+	if len(words) > maxSuggestionWords {
+		words = words[len(words)-maxSuggestionWords:]
+	}
+
+	site := slug(siteName(destination.Hostname()), sep)
+
+	var bases []string
+	if len(words) > 0 {
+		last := words[len(words)-1]
+		bases = append(bases, last, strings.Join(words, sep), join(sep, site, last))
+	}
+	bases = append(bases, site)
+
+	seen := make(map[string]struct{})
+	var suggestions []string
+	add := func(base string, suffix string) {
+		if base == "" {
+			return
+		}
+		// bases too long for the policy are skipped rather than cut in the middle of a word
+		alias, err := p.Normalize(base + suffix)
+		if err != nil {
+			return
+		}
+		if _, ok := seen[strings.ToLower(alias)]; ok {
+			return
+		}
+		seen[strings.ToLower(alias)] = struct{}{}
+		suggestions = append(suggestions, alias)
+	}
+
+	for _, base := range bases {
+		add(base, "")
+	}
+	for n := 2; n <= maxSuggestionNumber; n++ {
+		for _, base := range bases {
+			add(base, sep+strconv.Itoa(n))
+		}
+	}
+
+	return suggestions
+}
+
+// separator returns the character joining words of suggestions: '-' or '_' if the charset allows them.
+func (p *Policy) separator() string {
+	for _, sep := range []string{"-", "_"} {
+		if strings.Contains(p.charset, sep) {
+			return sep
+		}
+	}
+	return ""
+}
+
+// slug lowercases the text and replaces runs of characters, which aren't latin letters or digits, by separator.
+func slug(text string, sep string) string {
+	if unescaped, err := url.PathUnescape(text); err == nil {
+		text = unescaped
+	}
+
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(text) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteString(sep)
+			pending = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// siteName returns the most distinctive label of host: the one before top-level domain, e.g. github of www.github.com.
+func siteName(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return host
+	}
+	return labels[len(labels)-2]
+}
+
+// join joins not empty words by separator.
+func join(sep string, words ...string) string {
+	var nonEmpty []string
+	for _, word := range words {
+		if word != "" {
+			nonEmpty = append(nonEmpty, word)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+func hasLetter(word string) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return r >= 'a' && r <= 'z' }) >= 0
+}
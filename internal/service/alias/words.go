@@ -0,0 +1,79 @@
+package alias
+
+import (
+	"backend/internal/lib/random"
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// MaxWordsLength is the maximum length of words aliases, longer ones wouldn't fit in database.
+const MaxWordsLength = 12
+
+// wordsLength is the part of alias length taken by words, the rest of length is filled by digits.
+const wordsLength = 4
+
+// adjectives and nouns are short and easy to spell, so aliases are easy to dictate.
+var (
+	adjectives = []string{
+		"able", "bold", "brave", "brisk", "calm", "clean", "clear", "cool",
+		"cozy", "crisp", "daily", "eager", "early", "easy", "fair", "fancy",
+		"fast", "fine", "firm", "fresh", "glad", "gold", "good", "grand",
+		"great", "green", "happy", "keen", "kind", "light", "lucky", "merry",
+		"mild", "neat", "nice", "noble", "proud", "quick", "quiet", "rapid",
+		"ready", "rich", "royal", "safe", "sharp", "shiny", "silly", "smart",
+		"snowy", "soft", "solid", "sunny", "super", "sweet", "swift", "tidy",
+		"tiny", "true", "vast", "vivid", "warm", "wise", "witty", "young",
+	}
+	nouns = []string{
+		"apple", "bear", "bird", "boat", "bread", "cake", "cat", "cloud",
+		"coast", "crane", "deer", "dog", "dove", "eagle", "field", "fish",
+		"fox", "frog", "goat", "grape", "hawk", "hill", "horse", "koala",
+		"lake", "leaf", "lemon", "lion", "llama", "maple", "moon", "moose",
+		"mouse", "mango", "night", "ocean", "otter", "owl", "panda", "peach",
+		"pearl", "pine", "plum", "pond", "quail", "river", "robin", "rose",
+		"sea", "seal", "shark", "sheep", "snail", "star", "stone", "storm",
+		"sun", "swan", "tiger", "tree", "tulip", "wave", "whale", "wolf",
+	}
+)
+
+// Words generates pronounceable aliases of an adjective, a noun and a number, e.g. brave-otter-42.
+type Words struct{}
+
+// NewWords returns a new instance of *Words.
+func NewWords() *Words {
+	return &Words{}
+}
+
+// Generate returns a random words alias. The length sets the number of digits: length-4, but at least one.
+func (g *Words) Generate(_ context.Context, length int) (string, error) {
+	adjective, err := pick(adjectives)
+	if err != nil {
+		return "", err
+	}
+	noun, err := pick(nouns)
+	if err != nil {
+		return "", err
+	}
+
+	digits := length - wordsLength
+	if digits < 1 {
+		digits = 1
+	}
+	number, err := random.GenerateFrom(digits, "0123456789")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{adjective, noun, number}, "-"), nil
+}
+
+// pick returns a random word of the list.
+func pick(words []string) (string, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(words))))
+	if err != nil {
+		return "", err
+	}
+	return words[i.Int64()], nil
+}
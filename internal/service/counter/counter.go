@@ -0,0 +1,229 @@
+package counter
+
+import (
+	"backend/internal/config"
+	"backend/internal/lib/logger/sl"
+	"backend/internal/service/repository/postgres/click"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// flushTimeout is the maximum duration of one flush to database.
+const flushTimeout = 10 * time.Second
+
+// Flusher persists aggregated redirects counters increments, mapped by url ID.
+type Flusher interface {
+	IncrementRedirectsCounters(ctx context.Context, increments map[string]int) error
+}
+
+// ClickSaver persists click events.
+type ClickSaver interface {
+	CreateMany(ctx context.Context, clicks []click.Click) error
+}
+
+// Counter is an in-process buffered redirects counter. Every redirect is a click event: clicks are saved
+// to ClickSaver and their increments are aggregated by url ID and flushed to Flusher on interval,
+// or when the number of pending urls or clicks reaches the flush size. Clicks of redirects, which are
+// already counted in database, are added by AddClick and only saved.
+type Counter struct {
+	flusher   Flusher
+	clicks    ClickSaver
+	log       *slog.Logger
+	interval  time.Duration
+	flushSize int
+
+	mu     sync.RWMutex
+	closed bool
+	events chan bufferedClick
+	done   chan struct{}
+
+	enqueued     atomic.Int64
+	dropped      atomic.Int64
+	flushed      atomic.Int64
+	failed       atomic.Int64
+	clicksFailed atomic.Int64
+}
+
+// bufferedClick is a click waiting in buffer. Counted clicks don't increment redirects counters on flush.
+type bufferedClick struct {
+	click   click.Click
+	counted bool
+}
+
+// Stats is a snapshot of Counter metrics.
+type Stats struct {
+	Enqueued int64 `json:"enqueued"` // clicks accepted to buffer
+	Dropped  int64 `json:"dropped"`  // clicks rejected because buffer was full or counter was closed
+	Flushed  int64 `json:"flushed"`  // increments persisted by flusher
+	Failed   int64 `json:"failed"`   // increments lost because flusher returned an error
+	Pending  int   `json:"pending"`  // clicks waiting in buffer
+
+	ClicksFailed int64 `json:"clicks_failed"` // click events lost because click saver returned an error
+}
+
+// New returns a new instance of *Counter. Call Start to begin flushing.
+func New(flusher Flusher, clicks ClickSaver, cfg config.RedirectCounter, log *slog.Logger) *Counter {
+	return &Counter{
+		flusher:   flusher,
+		clicks:    clicks,
+		log:       log.With(slog.String("op", "counter.Counter")),
+		interval:  cfg.FlushInterval,
+		flushSize: cfg.FlushSize,
+		events:    make(chan bufferedClick, cfg.BufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start starts a background goroutine, which aggregates and flushes increments.
+func (c *Counter) Start() {
+	go c.run()
+}
+
+// Add records the click and increments the redirects counter of its url. It never blocks:
+// if the buffer is full, the click is dropped.
+func (c *Counter) Add(event click.Click) {
+	c.add(bufferedClick{click: event})
+}
+
+// AddClick records the click of a redirect, which is already counted in database. It never blocks:
+// if the buffer is full, the click is dropped.
+func (c *Counter) AddClick(event click.Click) {
+	c.add(bufferedClick{click: event, counted: true})
+}
+
+func (c *Counter) add(event bufferedClick) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		c.dropped.Add(1)
+		return
+	}
+
+	select {
+	case c.events <- event:
+		c.enqueued.Add(1)
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// Close stops accepting clicks and waits until all buffered clicks and increments are flushed,
+// or the context is done.
+func (c *Counter) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.events)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of Counter metrics.
+func (c *Counter) Stats() Stats {
+	return Stats{
+		Enqueued: c.enqueued.Load(),
+		Dropped:  c.dropped.Load(),
+		Flushed:  c.flushed.Load(),
+		Failed:   c.failed.Load(),
+		Pending:  len(c.events),
+
+		ClicksFailed: c.clicksFailed.Load(),
+	}
+}
+
+func (c *Counter) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]int)
+	var clicks []click.Click
+
+	for {
+		select {
+		case event, ok := <-c.events:
+			if !ok {
+				c.flush(pending)
+				c.saveClicks(clicks)
+				return
+			}
+
+			if !event.counted {
+				pending[event.click.UrlID]++
+			}
+			clicks = append(clicks, event.click)
+			if len(pending) >= c.flushSize || len(clicks) >= c.flushSize {
+				c.flush(pending)
+				c.saveClicks(clicks)
+				pending, clicks = make(map[string]int), nil
+			}
+		case <-ticker.C:
+			if len(pending) > 0 || len(clicks) > 0 {
+				c.flush(pending)
+				c.saveClicks(clicks)
+				pending, clicks = make(map[string]int), nil
+			}
+		}
+	}
+}
+
+// saveClicks persists pending click events and updates metrics.
+func (c *Counter) saveClicks(clicks []click.Click) {
+	if len(clicks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := c.clicks.CreateMany(ctx, clicks); err != nil {
+		c.clicksFailed.Add(int64(len(clicks)))
+		c.log.Error("error occurred while saving clicks",
+			slog.Int("clicks", len(clicks)),
+			sl.Err(err),
+		)
+	}
+}
+
+// flush persists pending increments and updates metrics.
+func (c *Counter) flush(pending map[string]int) {
+	if len(pending) == 0 {
+		return
+	}
+
+	var total int64
+	for _, n := range pending {
+		total += int64(n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := c.flusher.IncrementRedirectsCounters(ctx, pending); err != nil {
+		c.failed.Add(total)
+		c.log.Error("error occurred while flushing redirects counters",
+			slog.Int("urls", len(pending)),
+			slog.Int64("increments", total),
+			sl.Err(err),
+		)
+		return
+	}
+
+	c.flushed.Add(total)
+	c.log.Debug("redirects counters flushed",
+		slog.Int("urls", len(pending)),
+		slog.Int64("increments", total),
+	)
+}
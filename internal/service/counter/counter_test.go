@@ -0,0 +1,119 @@
+package counter
+
+import (
+	"backend/internal/config"
+	"backend/internal/service/repository/postgres/click"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeFlusher struct {
+	mu     sync.Mutex
+	err    error
+	counts map[string]int
+	clicks []string
+}
+
+func (f *fakeFlusher) IncrementRedirectsCounters(_ context.Context, increments map[string]int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	for id, n := range increments {
+		f.counts[id] += n
+	}
+	return nil
+}
+
+func (f *fakeFlusher) CreateMany(_ context.Context, clicks []click.Click) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	for _, c := range clicks {
+		f.clicks = append(f.clicks, c.UrlID)
+	}
+	return nil
+}
+
+func newTestCounter(flusher *fakeFlusher, bufferSize int) *Counter {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(flusher, flusher, config.RedirectCounter{
+		FlushInterval: time.Hour,
+		FlushSize:     100,
+		BufferSize:    bufferSize,
+	}, log)
+}
+
+func TestCounter_CloseFlushesAggregatedIncrements(t *testing.T) {
+	flusher := &fakeFlusher{counts: make(map[string]int)}
+	c := newTestCounter(flusher, 10)
+	c.Start()
+
+	for _, id := range []string{"a", "b", "a", "a"} {
+		c.Add(click.Click{UrlID: id})
+	}
+	c.AddClick(click.Click{UrlID: "c"}) // redirect counted in database, only the click is saved
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := map[string]int{"a": 3, "b": 1}
+	if !reflect.DeepEqual(flusher.counts, want) {
+		t.Errorf("flushed counts = %v, want %v", flusher.counts, want)
+	}
+	if wantClicks := []string{"a", "b", "a", "a", "c"}; !reflect.DeepEqual(flusher.clicks, wantClicks) {
+		t.Errorf("saved clicks = %v, want %v", flusher.clicks, wantClicks)
+	}
+
+	stats := c.Stats()
+	if stats.Enqueued != 5 || stats.Flushed != 4 || stats.Dropped != 0 {
+		t.Errorf("Stats() = %+v, want 5 enqueued and 4 flushed", stats)
+	}
+}
+
+func TestCounter_DropsWhenBufferIsFull(t *testing.T) {
+	flusher := &fakeFlusher{counts: make(map[string]int)}
+	c := newTestCounter(flusher, 2)
+
+	// counter isn't started, so nothing drains the buffer
+	for i := 0; i < 5; i++ {
+		c.Add(click.Click{UrlID: "a"})
+	}
+
+	stats := c.Stats()
+	if stats.Enqueued != 2 || stats.Dropped != 3 || stats.Pending != 2 {
+		t.Errorf("Stats() = %+v, want 2 enqueued, 3 dropped and 2 pending", stats)
+	}
+}
+
+func TestCounter_CountsFailedFlushes(t *testing.T) {
+	flusher := &fakeFlusher{counts: make(map[string]int), err: errors.New("database is down")}
+	c := newTestCounter(flusher, 10)
+	c.Start()
+
+	c.Add(click.Click{UrlID: "a"})
+	c.Add(click.Click{UrlID: "b"})
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	c.Add(click.Click{UrlID: "c"})
+
+	stats := c.Stats()
+	if stats.Failed != 2 || stats.ClicksFailed != 2 || stats.Flushed != 0 || stats.Dropped != 1 {
+		t.Errorf("Stats() = %+v, want 2 failed increments and clicks and 1 dropped after close", stats)
+	}
+}
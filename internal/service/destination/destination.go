@@ -0,0 +1,316 @@
+package destination
+
+import (
+	"backend/internal/config"
+	repoDomain "backend/internal/service/repository/postgres/domain"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSchemeNotAllowed = errors.New("url scheme is not allowed")
+	ErrInvalidHost      = errors.New("url host is invalid")
+	ErrDomainDenied     = errors.New("url domain is blocked")
+	ErrDomainNotAllowed = errors.New("url domain is not allowed")
+	ErrRedirectLoop     = errors.New("url points to a short url")
+	ErrPrivateNetwork   = errors.New("url points to a private network")
+)
+
+// violations are errors of urls breaking the policy, other errors of Check are failures of lookups.
+var violations = []error{ErrSchemeNotAllowed, ErrInvalidHost, ErrDomainDenied, ErrDomainNotAllowed, ErrRedirectLoop, ErrPrivateNetwork}
+
+// localSuffixes are suffixes of names, which resolve only in local networks.
+var localSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+// sharedAddressSpace is the carrier-grade NAT range, which isn't reachable from the internet.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Domains finds verified custom domains, which serve short urls.
+type Domains interface {
+	GetVerifiedByHost(ctx context.Context, host string) (repoDomain.Domain, error)
+}
+
+// Resolver looks up IP addresses of hosts. *net.Resolver implements it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Policy checks urls, which short urls redirect to.
+type Policy struct {
+	schemes      map[string]struct{}
+	allowed      []string
+	denied       []string
+	shortHosts   map[string]struct{}
+	blockPrivate bool
+	resolve      bool
+	domains      Domains
+	resolver     Resolver
+}
+
+// New returns a new instance of *Policy configured by cfg. Host of baseURL is a short domain as well as
+// custom domains found by domains. The denylist file is read once.
+func New(cfg config.Destination, baseURL string, domains Domains, resolver Resolver) (*Policy, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("destination: invalid base url: %w", err)
+	}
+
+	schemes := make(map[string]struct{}, len(cfg.Schemes))
+	for _, scheme := range cfg.Schemes {
+		schemes[strings.ToLower(scheme)] = struct{}{}
+	}
+
+	shortHosts := map[string]struct{}{normalizeDomain(base.Hostname()): {}}
+	for _, host := range cfg.ShortDomains {
+		shortHosts[normalizeDomain(host)] = struct{}{}
+	}
+
+	denied := normalizeDomains(cfg.DeniedDomains)
+	if cfg.DenylistPath != "" {
+		listed, err := readDenylist(cfg.DenylistPath)
+		if err != nil {
+			return nil, err
+		}
+		denied = append(denied, listed...)
+	}
+
+	return &Policy{
+		schemes:      schemes,
+		allowed:      normalizeDomains(cfg.AllowedDomains),
+		denied:       denied,
+		shortHosts:   shortHosts,
+		blockPrivate: !cfg.AllowPrivateNetworks,
+		resolve:      cfg.ResolveHosts,
+		domains:      domains,
+		resolver:     resolver,
+	}, nil
+}
+
+// Check checks, that the url can be a destination of short urls. If the url breaks a rule of the policy,
+// the function returns an error describing the rule, which IsViolation reports.
+// Urls without host, e.g. mailto:, are checked only by scheme.
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidHost
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if _, ok := p.schemes[scheme]; !ok {
+		return fmt.Errorf("%w: %s", ErrSchemeNotAllowed, scheme)
+	}
+
+	host := normalizeDomain(u.Hostname())
+	if host == "" {
+		if u.Opaque != "" {
+			return nil
+		}
+		return ErrInvalidHost
+	}
+
+	if matchDomain(host, p.denied) {
+		return ErrDomainDenied
+	}
+	if len(p.allowed) > 0 && !matchDomain(host, p.allowed) {
+		return ErrDomainNotAllowed
+	}
+
+	if err = p.checkLoop(ctx, host); err != nil {
+		return err
+	}
+
+	if p.blockPrivate {
+		return p.checkPrivate(ctx, host)
+	}
+
+	return nil
+}
+
+// IsViolation reports whether err returned by Check means, that the url breaks the policy.
+func IsViolation(err error) bool {
+	for _, violation := range violations {
+		if errors.Is(err, violation) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLoop checks, that the host doesn't serve short urls, so redirects can't loop.
+// Not verified custom domains don't serve urls, so claiming a host doesn't block urls to it.
+func (p *Policy) checkLoop(ctx context.Context, host string) error {
+	if _, ok := p.shortHosts[host]; ok {
+		return ErrRedirectLoop
+	}
+
+	_, err := p.domains.GetVerifiedByHost(ctx, host)
+	if repoDomain.IsErrDomainNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return ErrRedirectLoop
+}
+
+// checkPrivate checks, that the host isn't in a local network: its IP address or local name,
+// and addresses of its name, if the policy resolves hosts. Hosts, which can't be resolved, aren't blocked.
+func (p *Policy) checkPrivate(ctx context.Context, host string) error {
+	ip, err := parseIP(host)
+	if err != nil {
+		return err
+	}
+	if ip != nil {
+		if isPrivate(ip) {
+			return ErrPrivateNetwork
+		}
+		return nil
+	}
+
+	if !strings.Contains(host, ".") || host == "localhost" {
+		return ErrPrivateNetwork
+	}
+	for _, suffix := range localSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return ErrPrivateNetwork
+		}
+	}
+
+	if !p.resolve {
+		return nil
+	}
+
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isPrivate(addr.IP) {
+			return ErrPrivateNetwork
+		}
+	}
+
+	return nil
+}
+
+// parseIP parses the host as browsers do: besides usual notations, IPv4 addresses may be written
+// by fewer numbers, e.g. 127.1, and in hex or octal, e.g. 0x7f000001. If the host is a domain name,
+// the function returns nil. Hosts ending with a number, which aren't valid addresses, are invalid.
+func parseIP(host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+
+	parts := strings.Split(host, ".")
+	last := parts[len(parts)-1]
+	if last == "" || !strings.ContainsAny(last[:1], "0123456789") {
+		return nil, nil
+	}
+	if len(parts) > 4 {
+		return nil, ErrInvalidHost
+	}
+
+	var addr uint64
+	for i, part := range parts {
+		n, err := parseIPNumber(part)
+		if err != nil {
+			return nil, ErrInvalidHost
+		}
+
+		if i < len(parts)-1 {
+			if n > 255 {
+				return nil, ErrInvalidHost
+			}
+			addr |= n << (8 * (3 - i))
+			continue
+		}
+
+		// the last number fills all remaining bytes
+		if n >= 1<<(8*(4-i)) {
+			return nil, ErrInvalidHost
+		}
+		addr |= n
+	}
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr)), nil
+}
+
+// parseIPNumber parses a number of IPv4 address: hex with 0x prefix, octal with leading zero, or decimal.
+func parseIPNumber(s string) (uint64, error) {
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X"):
+		s, base = s[2:], 16
+	case len(s) > 1 && s[0] == '0':
+		s, base = s[1:], 8
+	}
+	if s == "" && base == 16 {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, base, 32)
+}
+
+// isPrivate reports whether the address isn't reachable from the internet.
+func isPrivate(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// matchDomain reports whether the host is one of domains or their subdomain.
+func matchDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomain lowercases the domain and trims the root dot.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// normalizeDomains normalizes domains of the list and skips empty ones.
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+// readDenylist reads domains of denylist file: a domain per line, empty lines and lines starting with # are skipped.
+func readDenylist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("destination: can't open denylist: %w", err)
+	}
+	defer file.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("destination: can't read denylist: %w", err)
+	}
+
+	return normalizeDomains(domains), nil
+}
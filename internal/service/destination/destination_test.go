@@ -0,0 +1,128 @@
+package destination
+
+import (
+	"backend/internal/config"
+	repoDomain "backend/internal/service/repository/postgres/domain"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var errDatabase = errors.New("database is down")
+
+// fakeDomains has custom domains by hosts, mapped to whether they're verified; the host broken.example fails.
+type fakeDomains map[string]bool
+
+func (d fakeDomains) GetVerifiedByHost(_ context.Context, host string) (repoDomain.Domain, error) {
+	if host == "broken.example" {
+		return repoDomain.Domain{}, errDatabase
+	}
+	if !d[host] {
+		return repoDomain.Domain{}, repoDomain.ErrDomainNotFound
+	}
+	return repoDomain.Domain{Host: host}, nil
+}
+
+// fakeResolver resolves hosts to addresses, unknown hosts aren't found.
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	addr, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(addr)}}, nil
+}
+
+func TestPolicyCheck(t *testing.T) {
+	denylist := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(denylist, []byte("# phishing\nPhish.example.\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Destination{
+		Schemes:       []string{"http", "https", "mailto"},
+		DeniedDomains: []string{"evil.example"},
+		DenylistPath:  denylist,
+		ShortDomains:  []string{"mk.sh"},
+		ResolveHosts:  true,
+	}
+	domains := fakeDomains{"links.example.com": true, "github.com": false}
+	resolver := fakeResolver{"rebind.example.com": "10.0.0.1", "example.com": "93.184.216.34"}
+
+	tests := []struct {
+		name    string
+		url     string
+		allowed []string
+		wantErr error
+	}{
+		{name: "Public url", url: "https://example.com/page"},
+		{name: "Unresolved host", url: "https://unknown.example.org"},
+		{name: "Url without host", url: "mailto:team@example.com"},
+		{name: "JavaScript", url: "javascript:alert(1)", wantErr: ErrSchemeNotAllowed},
+		{name: "Scheme case", url: "JavaScript:alert(1)", wantErr: ErrSchemeNotAllowed},
+		{name: "Data", url: "data:text/html,<script>alert(1)</script>", wantErr: ErrSchemeNotAllowed},
+		{name: "File", url: "file:///etc/passwd", wantErr: ErrSchemeNotAllowed},
+		{name: "Empty host", url: "https:///path", wantErr: ErrInvalidHost},
+		{name: "Denied domain", url: "https://evil.example", wantErr: ErrDomainDenied},
+		{name: "Denied subdomain", url: "https://www.EVIL.example./", wantErr: ErrDomainDenied},
+		{name: "Denylist file", url: "https://login.phish.example", wantErr: ErrDomainDenied},
+		{name: "Similar domain isn't denied", url: "https://notevil.example"},
+		{name: "Not allowed domain", url: "https://example.com", allowed: []string{"example.org"}, wantErr: ErrDomainNotAllowed},
+		{name: "Allowed subdomain", url: "https://www.example.org", allowed: []string{"example.org"}},
+		{name: "Base url host", url: "http://localhost:7531/s/abc", wantErr: ErrRedirectLoop},
+		{name: "Short domain", url: "https://MK.SH/abc", wantErr: ErrRedirectLoop},
+		{name: "Custom domain", url: "https://links.example.com/abc", wantErr: ErrRedirectLoop},
+		{name: "Not verified custom domain", url: "https://github.com/makeshort/backend"},
+		{name: "Failed domain lookup", url: "https://broken.example", wantErr: errDatabase},
+		{name: "Loopback", url: "http://127.0.0.1/admin", wantErr: ErrPrivateNetwork},
+		{name: "Short loopback", url: "http://127.1/", wantErr: ErrPrivateNetwork},
+		{name: "Decimal loopback", url: "http://2130706433/", wantErr: ErrPrivateNetwork},
+		{name: "Hex private address", url: "http://0xa.0x0.0x0.0x1/", wantErr: ErrPrivateNetwork},
+		{name: "Octal private address", url: "http://0300.0250.0.1/", wantErr: ErrPrivateNetwork},
+		{name: "Invalid address", url: "http://1.2.3.256/", wantErr: ErrInvalidHost},
+		{name: "Public address", url: "http://8.8.8.8/"},
+		{name: "IPv6 loopback", url: "http://[::1]:8080/", wantErr: ErrPrivateNetwork},
+		{name: "Mapped IPv6 loopback", url: "http://[::ffff:127.0.0.1]/", wantErr: ErrPrivateNetwork},
+		{name: "Link-local metadata address", url: "http://169.254.169.254/latest", wantErr: ErrPrivateNetwork},
+		{name: "Shared address space", url: "http://100.64.0.1/", wantErr: ErrPrivateNetwork},
+		{name: "Local name", url: "http://printer.local/", wantErr: ErrPrivateNetwork},
+		{name: "Single label name", url: "http://router/", wantErr: ErrPrivateNetwork},
+		{name: "Domain resolving to private address", url: "https://rebind.example.com", wantErr: ErrPrivateNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.AllowedDomains = tt.allowed
+
+			p, err := New(cfg, "http://localhost:7531", domains, resolver)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = p.Check(context.Background(), tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && IsViolation(err) != !errors.Is(err, errDatabase) {
+				t.Errorf("IsViolation(%v) = %v", err, IsViolation(err))
+			}
+		})
+	}
+}
+
+func TestPolicyCheckWithPrivateNetworksAllowed(t *testing.T) {
+	cfg := config.Destination{Schemes: []string{"http"}, AllowPrivateNetworks: true}
+	p, err := New(cfg, "https://mk.sh", fakeDomains{}, fakeResolver{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err = p.Check(context.Background(), "http://192.168.0.1/"); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+const (
+	// RecordPrefix is prepended to the host to get the name of verification TXT record.
+	RecordPrefix = "_makeshort."
+	// ValuePrefix is prepended to the verification token to get the value of verification TXT record.
+	ValuePrefix = "makeshort-verification="
+)
+
+var ErrInvalidHost = errors.New("host must be a valid domain name")
+
+// Resolver looks up DNS TXT records. *net.Resolver implements it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type Verifier struct {
+	resolver Resolver
+}
+
+// NewVerifier returns a new instance of *Verifier, which looks up records with given resolver.
+func NewVerifier(resolver Resolver) *Verifier {
+	return &Verifier{resolver: resolver}
+}
+
+// Verify checks if the host has a TXT record with given verification token.
+// If the record does not exist, the function returns false without error.
+func (v *Verifier) Verify(ctx context.Context, host string, token string) (bool, error) {
+	records, err := v.resolver.LookupTXT(ctx, RecordName(host))
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	want := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RecordName returns the name of verification TXT record of the host.
+func RecordName(host string) string {
+	return RecordPrefix + host
+}
+
+// RecordValue returns the value of verification TXT record with the token.
+func RecordValue(token string) string {
+	return ValuePrefix + token
+}
+
+// GenerateToken generates a new random verification token.
+func GenerateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NormalizeHost returns host in lower case, without port and trailing dot.
+// If the host is not a valid domain name (e.g. an IP address), the function will return an ErrInvalidHost.
+func NormalizeHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	if len(host) == 0 || len(host) > 253 || net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return "", ErrInvalidHost
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if !isValidLabel(label) {
+			return "", ErrInvalidHost
+		}
+	}
+
+	return host, nil
+}
+
+// isValidLabel checks if s is a valid domain name label: up to 63 letters, digits and hyphens, not starting or ending with a hyphen.
+func isValidLabel(s string) bool {
+	if len(s) == 0 || len(s) > 63 || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
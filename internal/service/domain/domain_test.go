@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver fakeResolver
+		host     string
+		token    string
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "Record with token exists",
+			resolver: fakeResolver{records: map[string][]string{
+				"_makeshort.go.acme.com": {"v=spf1 -all", "makeshort-verification=token"},
+			}},
+			host:  "go.acme.com",
+			token: "token",
+			want:  true,
+		},
+		{
+			name: "Record with another token",
+			resolver: fakeResolver{records: map[string][]string{
+				"_makeshort.go.acme.com": {"makeshort-verification=another"},
+			}},
+			host:  "go.acme.com",
+			token: "token",
+			want:  false,
+		},
+		{
+			name:     "No record",
+			resolver: fakeResolver{records: map[string][]string{}},
+			host:     "go.acme.com",
+			token:    "token",
+			want:     false,
+		},
+		{
+			name:     "Resolver failure",
+			resolver: fakeResolver{err: errors.New("timeout")},
+			host:     "go.acme.com",
+			token:    "token",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVerifier(tt.resolver).Verify(context.Background(), tt.host, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Lower case", host: "Go.Acme.COM", want: "go.acme.com"},
+		{name: "With port and trailing dot", host: "go.acme.com.:8080", want: "go.acme.com"},
+		{name: "IP address", host: "127.0.0.1", wantErr: true},
+		{name: "Single label", host: "localhost", wantErr: true},
+		{name: "Invalid characters", host: "go_acme.com", wantErr: true},
+		{name: "Label starts with hyphen", host: "-go.acme.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeHost(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeHost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package geoip
+
+import (
+	"backend/internal/config"
+	"backend/internal/lib/logger/sl"
+	"github.com/oschwald/maxminddb-golang"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reader resolves countries of IP addresses from a MaxMind-format database file.
+// The file is read into memory, so it can be replaced at any time, and is reloaded when it changes.
+type Reader struct {
+	path     string
+	interval time.Duration
+	log      *slog.Logger
+
+	db      atomic.Pointer[maxminddb.Reader]
+	modTime time.Time // modification time of the loaded file, used by the watcher only
+	size    int64     // size of the loaded file, used by the watcher only
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Open loads a MaxMind-format (GeoIP2 / GeoLite2 Country or City) database file and returns a new instance of *Reader.
+// If path is empty, the reader is disabled and resolves every IP to an empty country.
+// If the file can't be loaded, the function returns a usable reader resolving every IP to an empty country
+// together with the error, and the file is loaded by Start when it appears.
+func Open(cfg config.GeoIP, log *slog.Logger) (*Reader, error) {
+	r := &Reader{
+		path:     cfg.Path,
+		interval: cfg.ReloadInterval,
+		log:      log.With(slog.String("op", "geoip.Reader")),
+		stop:     make(chan struct{}),
+	}
+
+	if r.path == "" {
+		return r, nil
+	}
+
+	return r, r.load()
+}
+
+// Start starts watching the database file for changes in background.
+// Watching is disabled if path or reload interval is empty.
+func (r *Reader) Start() {
+	if r.path == "" || r.interval <= 0 {
+		return
+	}
+	go r.watch()
+}
+
+// Country returns ISO 3166-1 alpha-2 country code of given IP address.
+// If the country can't be resolved, the function will return an empty string.
+func (r *Reader) Country(ip string) string {
+	db := r.db.Load()
+	parsedIP := net.ParseIP(ip)
+	if db == nil || parsedIP == nil {
+		return ""
+	}
+
+	var rec record
+	if err := db.Lookup(parsedIP, &rec); err != nil {
+		return ""
+	}
+
+	return rec.Country.ISOCode
+}
+
+// Close stops watching the database file. The loaded database stays in memory until the reader is collected.
+func (r *Reader) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+	return nil
+}
+
+func (r *Reader) watch() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				// the file may be written right now, so it's loaded again on the next tick
+				r.log.Warn("error occurred while reloading geoip database, previous one is used", sl.Err(err))
+				continue
+			}
+			r.log.Info("geoip database reloaded", slog.String("path", r.path))
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// changed reports whether the database file was changed since it was loaded.
+// A removed file is not a change: the loaded database is kept.
+func (r *Reader) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// load reads the database file into memory and replaces the current database with it.
+func (r *Reader) load() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	buf, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return err
+	}
+
+	r.db.Store(db)
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// csvColumns lists accepted names of CSV columns, normalized by normalizeColumn.
+// Alias is taken from alias column, or from the last path segment of short url column.
+type csvColumns struct {
+	alias     []string
+	shortUrl  []string
+	longUrl   []string
+	clicks    []string
+	createdAt []string
+}
+
+var bitlyColumns = csvColumns{
+	alias:     []string{"backhalf", "custombackhalf", "keyword"},
+	shortUrl:  []string{"bitlink", "link", "shorturl", "shortlink"},
+	longUrl:   []string{"longurl", "destinationurl", "destination", "url"},
+	clicks:    []string{"clicks", "totalclicks", "userclicks"},
+	createdAt: []string{"created", "createdat", "createdatutc", "createddate", "datecreated"},
+}
+
+var shlinkColumns = csvColumns{
+	alias:     []string{"shortcode"},
+	shortUrl:  []string{"shorturl"},
+	longUrl:   []string{"longurl"},
+	clicks:    []string{"visits", "visitscount", "visitstotal"},
+	createdAt: []string{"createdat", "datecreated"},
+}
+
+var yourlsColumns = csvColumns{
+	alias:     []string{"keyword"},
+	longUrl:   []string{"url"},
+	clicks:    []string{"clicks"},
+	createdAt: []string{"timestamp"},
+}
+
+// timeLayouts are layouts of creation dates in export files.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// csvIndexes are indexes of known columns in CSV record, -1 if the column is missing.
+type csvIndexes struct {
+	alias, shortUrl, longUrl, clicks, createdAt int
+}
+
+// readCsv reads records from CSV with columns described by columns. The first CSV record is a header,
+// unless defaultHeader is set and the first record doesn't contain long url column.
+func readCsv(r io.Reader, columns csvColumns, defaultHeader []string, fn func(Record) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	indexes := columns.indexes(header)
+	isHeader := indexes.longUrl >= 0
+	if !isHeader {
+		if defaultHeader == nil {
+			return fmt.Errorf("%w: header with long url column is missing", ErrInvalidFile)
+		}
+		indexes = columns.indexes(defaultHeader)
+	}
+
+	record := header
+	if isHeader {
+		record, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+	}
+
+	for {
+		line, _ := reader.FieldPos(0)
+
+		if err = fn(indexes.record(line, record)); err != nil {
+			return err
+		}
+
+		record, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+	}
+}
+
+// indexes finds known columns in header.
+func (c csvColumns) indexes(header []string) csvIndexes {
+	normalized := make([]string, len(header))
+	for i, name := range header {
+		normalized[i] = normalizeColumn(name)
+	}
+
+	find := func(names []string) int {
+		for _, name := range names {
+			for i, column := range normalized {
+				if column == name {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	return csvIndexes{
+		alias:     find(c.alias),
+		shortUrl:  find(c.shortUrl),
+		longUrl:   find(c.longUrl),
+		clicks:    find(c.clicks),
+		createdAt: find(c.createdAt),
+	}
+}
+
+// record maps CSV record to Record.
+func (i csvIndexes) record(line int, fields []string) Record {
+	field := func(index int) string {
+		if index < 0 || index >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[index])
+	}
+
+	alias := field(i.alias)
+	if alias == "" {
+		alias = aliasFromShortUrl(field(i.shortUrl))
+	}
+
+	return Record{
+		Line:      line,
+		LongURL:   field(i.longUrl),
+		Alias:     alias,
+		Clicks:    parseClicks(field(i.clicks)),
+		CreatedAt: parseTime(field(i.createdAt)),
+	}
+}
+
+// normalizeColumn lowercases the column name and removes all characters except letters and digits,
+// so "Long URL", "long_url" and "longUrl" are the same column.
+func normalizeColumn(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// aliasFromShortUrl returns the path of short url like "bit.ly/abc" or "https://s.test/abc" without slashes.
+func aliasFromShortUrl(shortUrl string) string {
+	if i := strings.Index(shortUrl, "://"); i >= 0 {
+		shortUrl = shortUrl[i+3:]
+	}
+	if i := strings.IndexAny(shortUrl, "?#"); i >= 0 {
+		shortUrl = shortUrl[:i]
+	}
+
+	i := strings.Index(shortUrl, "/")
+	if i < 0 {
+		return ""
+	}
+	return strings.Trim(shortUrl[i:], "/")
+}
+
+// parseClicks parses clicks counter, invalid counters are treated as zero.
+func parseClicks(s string) int {
+	clicks, err := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+	if err != nil || clicks < 0 {
+		return 0
+	}
+	return clicks
+}
+
+// parseTime parses creation date in one of known layouts, unknown dates are treated as missing.
+func parseTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package importer
+
+import (
+	"backend/internal/service/alias"
+	"backend/internal/service/destination"
+	"backend/internal/service/repository/postgres/url"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats of export files of other shorteners.
+const (
+	FormatBitly  = "bitly"  // CSV export of Bitly links
+	FormatYourls = "yourls" // SQL dump or CSV export of YOURLS url table
+	FormatShlink = "shlink" // CSV export of Shlink short urls
+)
+
+// DefaultBatchSize is a default count of records saved to database in one transaction.
+const DefaultBatchSize = 1000
+
+var (
+	ErrUnknownFormat = errors.New("importer: unknown format")
+	ErrInvalidFile   = errors.New("importer: invalid file")
+)
+
+// Record is an url read from export file.
+type Record struct {
+	Line      int // line of the record in export file
+	LongURL   string
+	Alias     string
+	Clicks    int
+	CreatedAt *time.Time
+}
+
+// RowError describes a record which is not imported.
+type RowError struct {
+	Line  int
+	Alias string
+	Url   string
+	Error string
+}
+
+// Result is a summary of import.
+type Result struct {
+	Total     int // records read from export file
+	Imported  int // records saved to database
+	Conflicts int // records with aliases which already exist
+	Invalid   int // records with invalid url or alias, including records rejected by database
+}
+
+// Hooks are optional callbacks of import progress.
+type Hooks struct {
+	OnError   func(RowError)         // called for every record which is not imported
+	OnCreated func(aliases []string) // called with aliases of every saved batch
+}
+
+// Creator saves batches of urls, reporting conflicts of aliases per url.
+type Creator interface {
+	CreateBatch(ctx context.Context, userID string, dtos []url.DTO) ([]url.BatchResult, error)
+}
+
+// DestinationPolicy checks urls of records. Errors, which IsViolation doesn't report, abort the import.
+type DestinationPolicy interface {
+	Check(ctx context.Context, rawURL string) error
+}
+
+// Importer imports urls from export files of other shorteners, preserving their aliases and clicks counters.
+type Importer struct {
+	creator   Creator
+	urls      DestinationPolicy
+	batchSize int
+}
+
+// New returns a new instance of *Importer. If batchSize is not positive, DefaultBatchSize is used.
+func New(creator Creator, urls DestinationPolicy, batchSize int) *Importer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{
+		creator:   creator,
+		urls:      urls,
+		batchSize: batchSize,
+	}
+}
+
+// Read reads records of export file in provided format, calling fn for every record.
+// If the file can't be parsed, the function returns an error wrapping ErrInvalidFile.
+func Read(format string, r io.Reader, fn func(Record) error) error {
+	switch format {
+	case FormatBitly:
+		return readCsv(r, bitlyColumns, nil, fn)
+	case FormatYourls:
+		return readYourls(r, fn)
+	case FormatShlink:
+		return readCsv(r, shlinkColumns, nil, fn)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// Import reads export file in provided format and saves its records as urls of the user in batches.
+// Batches saved before an error stay in database, so the result is valid even if an error is returned.
+func (i *Importer) Import(ctx context.Context, userID string, format string, r io.Reader, hooks Hooks) (Result, error) {
+	var result Result
+
+	records := make([]Record, 0, i.batchSize)
+	dtos := make([]url.DTO, 0, i.batchSize)
+
+	flush := func() error {
+		if len(dtos) == 0 {
+			return nil
+		}
+
+		created, err := i.creator.CreateBatch(ctx, userID, dtos)
+		if err != nil {
+			return err
+		}
+
+		aliases := make([]string, 0, len(created))
+		for j, res := range created {
+			if url.IsErrShortUrlAlreadyExists(res.Err) {
+				result.Conflicts++
+				hooks.reportError(records[j], "alias already exists")
+				continue
+			}
+			if res.Err != nil {
+				result.Invalid++
+				hooks.reportError(records[j], res.Err.Error())
+				continue
+			}
+			result.Imported++
+			aliases = append(aliases, records[j].Alias)
+		}
+		if hooks.OnCreated != nil && len(aliases) > 0 {
+			hooks.OnCreated(aliases)
+		}
+
+		records = records[:0]
+		dtos = dtos[:0]
+		return nil
+	}
+
+	err := Read(format, r, func(rec Record) error {
+		result.Total++
+
+		longUrl, message := validateRecord(rec)
+		if message != "" {
+			result.Invalid++
+			hooks.reportError(rec, message)
+			return nil
+		}
+
+		err := i.urls.Check(ctx, longUrl)
+		if destination.IsViolation(err) {
+			result.Invalid++
+			hooks.reportError(rec, err.Error())
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		records = append(records, rec)
+		dtos = append(dtos, url.DTO{
+			LongURL:   longUrl,
+			ShortURL:  rec.Alias,
+			Redirects: rec.Clicks,
+			CreatedAt: rec.CreatedAt,
+		})
+		if len(dtos) == i.batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, flush()
+}
+
+// reportError calls OnError hook, if it is set.
+func (h Hooks) reportError(rec Record, message string) {
+	if h.OnError == nil {
+		return
+	}
+	h.OnError(RowError{
+		Line:  rec.Line,
+		Alias: rec.Alias,
+		Url:   rec.LongURL,
+		Error: message,
+	})
+}
+
+// validateRecord validates url and alias of the record. Aliases are kept as is, so links of other shortener
+// keep working: only the lengths of alias and url stored in database are checked, not the alias policy.
+// It returns the parsed url, or the reason why the record can't be imported.
+func validateRecord(rec Record) (string, string) {
+	if rec.Alias == "" {
+		return "", "alias is missing"
+	}
+	if utf8.RuneCountInString(rec.Alias) > alias.MaxLength {
+		return "", fmt.Sprintf("alias is longer than %d characters", alias.MaxLength)
+	}
+
+	parsedUrl, err := neturl.ParseRequestURI(rec.LongURL)
+	if err != nil {
+		return "", "url is invalid"
+	}
+	longUrl := parsedUrl.String()
+	if len(longUrl) > url.MaxLongURLLength {
+		return "", "url is too long"
+	}
+
+	return longUrl, ""
+}
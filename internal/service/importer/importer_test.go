@@ -0,0 +1,235 @@
+package importer
+
+import (
+	"backend/internal/service/destination"
+	"backend/internal/service/repository/postgres/url"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name    string
+		format  string
+		input   string
+		want    []Record
+		wantErr error
+	}{
+		{
+			name:   "bitly",
+			format: FormatBitly,
+			input: "Bitlink,Long URL,Title,Created,Clicks\n" +
+				"bit.ly/abc,https://example.com/a,A,2023-05-01 10:00:00,\"1,204\"\n" +
+				"https://bit.ly/def,https://example.com/b,B,,oops\n",
+			want: []Record{
+				{Line: 2, LongURL: "https://example.com/a", Alias: "abc", Clicks: 1204, CreatedAt: date("2023-05-01T10:00:00Z")},
+				{Line: 3, LongURL: "https://example.com/b", Alias: "def"},
+			},
+		},
+		{
+			name:    "bitly without header",
+			format:  FormatBitly,
+			input:   "bit.ly/abc,https://example.com/a\n",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:   "shlink",
+			format: FormatShlink,
+			input: "createdAt,domain,shortCode,shortUrl,longUrl,title,tags,visits\n" +
+				"2023-05-01T10:00:00Z,,abc,https://s.test/abc,https://example.com/a,,,7\n" +
+				"2023-05-02T10:00:00Z,,,https://s.test/def,https://example.com/b,,,0\n",
+			want: []Record{
+				{Line: 2, LongURL: "https://example.com/a", Alias: "abc", Clicks: 7, CreatedAt: date("2023-05-01T10:00:00Z")},
+				{Line: 3, LongURL: "https://example.com/b", Alias: "def", CreatedAt: date("2023-05-02T10:00:00Z")},
+			},
+		},
+		{
+			name:   "yourls csv with header",
+			format: FormatYourls,
+			input: "keyword,url,title,timestamp,ip,clicks\n" +
+				"abc,https://example.com/a,A,2023-05-01 10:00:00,127.0.0.1,3\n",
+			want: []Record{
+				{Line: 2, LongURL: "https://example.com/a", Alias: "abc", Clicks: 3, CreatedAt: date("2023-05-01T10:00:00Z")},
+			},
+		},
+		{
+			name:   "yourls csv without header",
+			format: FormatYourls,
+			input:  "abc,https://example.com/a,A,2023-05-01 10:00:00,127.0.0.1,3\n",
+			want: []Record{
+				{Line: 1, LongURL: "https://example.com/a", Alias: "abc", Clicks: 3, CreatedAt: date("2023-05-01T10:00:00Z")},
+			},
+		},
+		{
+			name:   "yourls sql",
+			format: FormatYourls,
+			input: "-- MySQL dump\n" +
+				"/*!40101 SET NAMES utf8 */;\n" +
+				"CREATE TABLE `yourls_url` (`keyword` varchar(100) NOT NULL, `url` text) ENGINE=InnoDB;\n" +
+				"INSERT INTO `yourls_options` VALUES (1,'version','1.9');\n" +
+				"INSERT INTO `yourls_url` (`keyword`, `url`, `title`, `timestamp`, `ip`, `clicks`) VALUES\n" +
+				"('abc','https://example.com/a?x=1;y=2','It\\'s ''quoted''','2023-05-01 10:00:00','127.0.0.1',5),\n" +
+				"('def','https://example.com/b',NULL,'2023-05-02 10:00:00','127.0.0.1',0);\n" +
+				"INSERT IGNORE INTO db.`yourls_url` VALUES ('ghi','https://example.com/c','C','2023-05-03 10:00:00','127.0.0.1',1);\n",
+			want: []Record{
+				{Line: 6, LongURL: "https://example.com/a?x=1;y=2", Alias: "abc", Clicks: 5, CreatedAt: date("2023-05-01T10:00:00Z")},
+				{Line: 7, LongURL: "https://example.com/b", Alias: "def", CreatedAt: date("2023-05-02T10:00:00Z")},
+				{Line: 8, LongURL: "https://example.com/c", Alias: "ghi", Clicks: 1, CreatedAt: date("2023-05-03T10:00:00Z")},
+			},
+		},
+		{
+			name:    "yourls sql unterminated string",
+			format:  FormatYourls,
+			input:   "INSERT INTO `yourls_url` VALUES ('abc','https://example.com/a);\n",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "yourls sql truncated column list",
+			format:  FormatYourls,
+			input:   "INSERT INTO url (",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "yourls sql truncated column name",
+			format:  FormatYourls,
+			input:   "INSERT INTO url (keyword",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "yourls sql truncated values",
+			format:  FormatYourls,
+			input:   "INSERT INTO url (keyword, url) VALUES ('abc', 'https://example.com/a'",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "unknown format",
+			format:  "tinyurl",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Record
+			err := Read(tc.format, strings.NewReader(tc.input), func(rec Record) error {
+				got = append(got, rec)
+				return nil
+			})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Read() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Read() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+type fakeCreator struct {
+	existing map[string]bool
+	rejected map[string]error // errors of database for aliases, other than conflicts
+	batches  int
+}
+
+func (c *fakeCreator) CreateBatch(_ context.Context, _ string, dtos []url.DTO) ([]url.BatchResult, error) {
+	c.batches++
+	results := make([]url.BatchResult, len(dtos))
+	for i, dto := range dtos {
+		if err := c.rejected[dto.ShortURL]; err != nil {
+			results[i].Err = err
+			continue
+		}
+		if c.existing[dto.ShortURL] {
+			results[i].Err = url.ErrShortUrlAlreadyExists
+			continue
+		}
+		c.existing[dto.ShortURL] = true
+		results[i].ID = "id-" + dto.ShortURL
+	}
+	return results, nil
+}
+
+// httpPolicy allows only http urls.
+type httpPolicy struct{}
+
+func (httpPolicy) Check(_ context.Context, rawURL string) error {
+	if !strings.HasPrefix(rawURL, "http") {
+		return destination.ErrSchemeNotAllowed
+	}
+	return nil
+}
+
+func TestImporter_Import(t *testing.T) {
+	input := "shortCode,longUrl,visits\n" +
+		"a,https://example.com/a,1\n" +
+		"taken,https://example.com/b,2\n" +
+		"c,not a url,3\n" +
+		",https://example.com/d,4\n" +
+		"E,https://example.com/e,5\n" +
+		"a,https://example.com/f,6\n" +
+		"api,https://example.com/g,7\n" +
+		"abcdefghijklmnopqrstu,https://example.com/h,8\n" +
+		"js,javascript:alert(1),9\n" +
+		"long,https://example.com/" + strings.Repeat("i", 2048) + ",10\n" +
+		"bad,https://example.com/j,11\n"
+
+	creator := &fakeCreator{
+		existing: map[string]bool{"taken": true},
+		rejected: map[string]error{"bad": errors.New("violates foreign key constraint")},
+	}
+	importer := New(creator, httpPolicy{}, 2)
+
+	var errs []RowError
+	var created []string
+	result, err := importer.Import(context.Background(), "", FormatShlink, strings.NewReader(input), Hooks{
+		OnError:   func(e RowError) { errs = append(errs, e) },
+		OnCreated: func(aliases []string) { created = append(created, aliases...) },
+	})
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+
+	wantResult := Result{Total: 11, Imported: 3, Conflicts: 2, Invalid: 6}
+	if result != wantResult {
+		t.Errorf("Import() = %+v, want %+v", result, wantResult)
+	}
+	if creator.batches != 3 {
+		t.Errorf("batches = %d, want 3", creator.batches)
+	}
+	// aliases are kept as is, even if the alias policy would reject them
+	if want := []string{"a", "E", "api"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created = %v, want %v", created, want)
+	}
+
+	wantErrs := []RowError{
+		{Line: 3, Alias: "taken", Url: "https://example.com/b", Error: "alias already exists"},
+		{Line: 4, Alias: "c", Url: "not a url", Error: "url is invalid"},
+		{Line: 5, Alias: "", Url: "https://example.com/d", Error: "alias is missing"},
+		{Line: 7, Alias: "a", Url: "https://example.com/f", Error: "alias already exists"},
+		{Line: 9, Alias: "abcdefghijklmnopqrstu", Url: "https://example.com/h", Error: "alias is longer than 20 characters"},
+		{Line: 10, Alias: "js", Url: "javascript:alert(1)", Error: "url scheme is not allowed"},
+		{Line: 11, Alias: "long", Url: "https://example.com/" + strings.Repeat("i", 2048), Error: "url is too long"},
+		{Line: 12, Alias: "bad", Url: "https://example.com/j", Error: "violates foreign key constraint"},
+	}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("errors = %+v, want %+v", errs, wantErrs)
+	}
+}
@@ -0,0 +1,383 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// yourlsDefaultHeader is the order of columns of YOURLS url table, used when CSV or INSERT statement has no column list.
+var yourlsDefaultHeader = []string{"keyword", "url", "title", "timestamp", "ip", "clicks"}
+
+// readYourls reads records of YOURLS url table from SQL dump or CSV export.
+// SQL dump is recognized by its first meaningful characters: a comment or an SQL statement.
+func readYourls(r io.Reader, fn func(Record) error) error {
+	br := bufio.NewReader(r)
+
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	if isSqlDump(head) {
+		return readYourlsSql(br, fn)
+	}
+	return readCsv(br, yourlsColumns, yourlsDefaultHeader, fn)
+}
+
+// isSqlDump reports whether the beginning of file looks like SQL dump.
+func isSqlDump(head []byte) bool {
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+	for _, prefix := range []string{"--", "/*", "#", "INSERT", "CREATE", "DROP", "SET", "LOCK", "USE"} {
+		if len(head) >= len(prefix) && strings.EqualFold(string(head[:len(prefix)]), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readYourlsSql reads records from INSERT statements into YOURLS url table, all other statements are skipped.
+func readYourlsSql(r io.Reader, fn func(Record) error) error {
+	l := newSqlLexer(r)
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == sqlTokenEOF:
+			return nil
+		case tok.isWord("INSERT"):
+			err = readYourlsInsert(l, fn)
+		default:
+			err = l.skipStatement(tok)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readYourlsInsert reads rows of INSERT statement after INSERT keyword.
+func readYourlsInsert(l *sqlLexer, fn func(Record) error) error {
+	tok, err := l.next()
+	if err != nil {
+		return err
+	}
+	if tok.isWord("IGNORE") {
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+	}
+	if !tok.isWord("INTO") {
+		return l.skipStatement(tok)
+	}
+
+	table, err := l.next()
+	if err != nil {
+		return err
+	}
+	name := strings.ToLower(table.text)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:] // database name
+	}
+	if table.kind != sqlTokenWord || (name != "url" && !strings.HasSuffix(name, "_url")) {
+		return l.skipStatement(table)
+	}
+
+	header := yourlsDefaultHeader
+	if tok, err = l.next(); err != nil {
+		return err
+	}
+	if tok.isPunct('(') {
+		header = nil
+		for {
+			if tok, err = l.next(); err != nil {
+				return err
+			}
+			if tok.isPunct(')') {
+				break
+			}
+			if tok.kind == sqlTokenEOF {
+				return fmt.Errorf("%w: line %d: unterminated column list", ErrInvalidFile, l.line)
+			}
+			if tok.kind == sqlTokenWord {
+				header = append(header, tok.text)
+			}
+		}
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+	}
+	if !tok.isWord("VALUES") && !tok.isWord("VALUE") {
+		return l.skipStatement(tok)
+	}
+
+	indexes := yourlsColumns.indexes(header)
+	if indexes.longUrl < 0 {
+		return fmt.Errorf("%w: line %d: url column is missing", ErrInvalidFile, l.line)
+	}
+
+	for {
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+		if !tok.isPunct('(') {
+			return fmt.Errorf("%w: line %d: values expected", ErrInvalidFile, l.line)
+		}
+		line := l.line
+
+		var values []string
+		for {
+			if tok, err = l.next(); err != nil {
+				return err
+			}
+			switch {
+			case tok.isPunct(')'):
+			case tok.isPunct(','):
+				continue
+			case tok.kind == sqlTokenString:
+				values = append(values, tok.text)
+				continue
+			case tok.isWord("NULL"):
+				values = append(values, "")
+				continue
+			case tok.kind == sqlTokenWord:
+				values = append(values, tok.text)
+				continue
+			default:
+				return fmt.Errorf("%w: line %d: unexpected %q in values", ErrInvalidFile, l.line, tok.text)
+			}
+			break
+		}
+
+		if err = fn(indexes.record(line, values)); err != nil {
+			return err
+		}
+
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+		if tok.isPunct(',') {
+			continue
+		}
+		if tok.isPunct(';') || tok.kind == sqlTokenEOF {
+			return nil
+		}
+		return fmt.Errorf("%w: line %d: unexpected %q after values", ErrInvalidFile, l.line, tok.text)
+	}
+}
+
+type sqlTokenKind int
+
+const (
+	sqlTokenEOF sqlTokenKind = iota
+	sqlTokenWord
+	sqlTokenString
+	sqlTokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+func (t sqlToken) isWord(word string) bool {
+	return t.kind == sqlTokenWord && strings.EqualFold(t.text, word)
+}
+
+func (t sqlToken) isPunct(r rune) bool {
+	return t.kind == sqlTokenPunct && t.text == string(r)
+}
+
+// sqlLexer splits MySQL dump into tokens: words (keywords, numbers and quoted identifiers),
+// strings and punctuation. Comments and whitespaces are skipped.
+type sqlLexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newSqlLexer(r io.Reader) *sqlLexer {
+	return &sqlLexer{r: bufio.NewReader(r), line: 1}
+}
+
+// skipStatement skips tokens until the end of statement, starting from the current token.
+func (l *sqlLexer) skipStatement(tok sqlToken) error {
+	var err error
+	for !tok.isPunct(';') && tok.kind != sqlTokenEOF {
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next returns the next token.
+func (l *sqlLexer) next() (sqlToken, error) {
+	for {
+		r, err := l.read()
+		if errors.Is(err, io.EOF) {
+			return sqlToken{kind: sqlTokenEOF}, nil
+		}
+		if err != nil {
+			return sqlToken{}, err
+		}
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\ufeff':
+			continue
+		case r == '#':
+			err = l.skipLine()
+		case r == '-' && l.peek() == '-':
+			err = l.skipLine()
+		case r == '/' && l.peek() == '*':
+			err = l.skipBlockComment()
+		case r == '\'' || r == '"':
+			return l.readString(r)
+		case r == '`':
+			return l.readIdentifier()
+		case strings.ContainsRune("(),;", r):
+			return sqlToken{kind: sqlTokenPunct, text: string(r)}, nil
+		default:
+			return l.readWord(r)
+		}
+		if err != nil {
+			return sqlToken{}, err
+		}
+	}
+}
+
+// read reads the next rune, counting lines.
+func (l *sqlLexer) read() (rune, error) {
+	r, _, err := l.r.ReadRune()
+	if r == '\n' {
+		l.line++
+	}
+	return r, err
+}
+
+// peek returns the next rune without reading it, or zero at the end of input.
+func (l *sqlLexer) peek() rune {
+	r, _, err := l.r.ReadRune()
+	if err != nil {
+		return 0
+	}
+	_ = l.r.UnreadRune()
+	return r
+}
+
+func (l *sqlLexer) skipLine() error {
+	for {
+		r, err := l.read()
+		if errors.Is(err, io.EOF) || r == '\n' {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (l *sqlLexer) skipBlockComment() error {
+	_, _ = l.read() // asterisk of the opening
+	var prev rune
+	for {
+		r, err := l.read()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: unterminated comment", ErrInvalidFile)
+		}
+		if err != nil {
+			return err
+		}
+		if prev == '*' && r == '/' {
+			return nil
+		}
+		prev = r
+	}
+}
+
+// readString reads a string quoted by quote, with backslash escapes and doubled quotes.
+func (l *sqlLexer) readString(quote rune) (sqlToken, error) {
+	var b strings.Builder
+	for {
+		r, err := l.read()
+		if errors.Is(err, io.EOF) {
+			return sqlToken{}, fmt.Errorf("%w: line %d: unterminated string", ErrInvalidFile, l.line)
+		}
+		if err != nil {
+			return sqlToken{}, err
+		}
+
+		switch {
+		case r == '\\':
+			r, err = l.read()
+			if err != nil {
+				return sqlToken{}, fmt.Errorf("%w: line %d: unterminated string", ErrInvalidFile, l.line)
+			}
+			switch r {
+			case 'n':
+				r = '\n'
+			case 'r':
+				r = '\r'
+			case 't':
+				r = '\t'
+			case '0':
+				r = 0
+			}
+		case r == quote && l.peek() == quote:
+			_, _ = l.read()
+		case r == quote:
+			return sqlToken{kind: sqlTokenString, text: b.String()}, nil
+		}
+		b.WriteRune(r)
+	}
+}
+
+// readIdentifier reads an identifier quoted by backticks.
+func (l *sqlLexer) readIdentifier() (sqlToken, error) {
+	var b strings.Builder
+	for {
+		r, err := l.read()
+		if errors.Is(err, io.EOF) {
+			return sqlToken{}, fmt.Errorf("%w: line %d: unterminated identifier", ErrInvalidFile, l.line)
+		}
+		if err != nil {
+			return sqlToken{}, err
+		}
+		if r == '`' {
+			if l.peek() != '`' {
+				return sqlToken{kind: sqlTokenWord, text: b.String()}, nil
+			}
+			_, _ = l.read()
+		}
+		b.WriteRune(r)
+	}
+}
+
+// readWord reads an unquoted word starting with first, until a whitespace, punctuation or quote.
+// Dotted names like db.`table` are read as one word.
+func (l *sqlLexer) readWord(first rune) (sqlToken, error) {
+	var b strings.Builder
+	b.WriteRune(first)
+	for {
+		r := l.peek()
+		switch {
+		case r == 0 || r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\'' || r == '"' || strings.ContainsRune("(),;", r):
+			return sqlToken{kind: sqlTokenWord, text: b.String()}, nil
+		case r == '`':
+			_, _ = l.read()
+			ident, err := l.readIdentifier()
+			if err != nil {
+				return sqlToken{}, err
+			}
+			b.WriteString(ident.text)
+		default:
+			_, _ = l.read()
+			b.WriteRune(r)
+		}
+	}
+}
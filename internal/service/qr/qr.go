@@ -0,0 +1,280 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // logos may be JPEG
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Formats of QR code images.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Limits and defaults of QR code options.
+const (
+	DefaultSize   = 256
+	MinSize       = 64
+	MaxSize       = 2048
+	DefaultMargin = 4 // quiet zone in modules, recommended by QR specification
+	MaxMargin     = 16
+)
+
+// logoScale is the maximum part of QR code width covered by logo. Highest error correction restores up to 30% of code.
+const logoScale = 0.2
+
+var (
+	ErrInvalidFormat = errors.New("qr: format must be png or svg")
+	ErrInvalidSize   = fmt.Errorf("qr: size must be between %d and %d", MinSize, MaxSize)
+	ErrInvalidMargin = fmt.Errorf("qr: margin must be between 0 and %d", MaxMargin)
+	ErrInvalidLevel  = errors.New("qr: ecc must be one of L, M, Q, H")
+	ErrInvalidColor  = errors.New("qr: color must be hex RGB or RGBA, e.g. 1a2b3c")
+	ErrSizeTooSmall  = errors.New("qr: size is too small for this content and margin")
+	ErrLogoNotFound  = errors.New("qr: logo is not configured")
+	ErrLogoSVG       = errors.New("qr: logo is supported only for png format")
+)
+
+// Options are rendering options of QR code.
+type Options struct {
+	Format     string
+	Size       int // width and height of image in pixels
+	Margin     int // quiet zone around the code in modules
+	Level      qrcode.RecoveryLevel
+	Foreground color.NRGBA
+	Background color.NRGBA
+	Logo       bool // embed configured logo in the center of PNG code
+}
+
+// DefaultOptions returns options of black PNG code on white background.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Margin:     DefaultMargin,
+		Level:      qrcode.Medium,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// Validate checks that options are in allowed ranges.
+func (o Options) Validate() error {
+	switch {
+	case o.Format != FormatPNG && o.Format != FormatSVG:
+		return ErrInvalidFormat
+	case o.Size < MinSize || o.Size > MaxSize:
+		return ErrInvalidSize
+	case o.Margin < 0 || o.Margin > MaxMargin:
+		return ErrInvalidMargin
+	case o.Logo && o.Format != FormatPNG:
+		return ErrLogoSVG
+	}
+	return nil
+}
+
+// ParseLevel parses error correction level: L, M, Q or H.
+func ParseLevel(s string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	default:
+		return 0, ErrInvalidLevel
+	}
+}
+
+// ParseColor parses a hex color in RGB, RRGGBB or RRGGBBAA notation, optionally prefixed by #.
+func ParseColor(s string) (color.NRGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Generator renders QR codes, optionally with a logo.
+type Generator struct {
+	logo image.Image
+}
+
+// New returns a new instance of *Generator. Logo may be nil, then codes with logo can't be rendered.
+func New(logo image.Image) *Generator {
+	return &Generator{logo: logo}
+}
+
+// LoadLogo loads PNG or JPEG logo from file. If the path is empty, the function returns nil logo.
+func LoadLogo(path string) (image.Image, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	logo, _, err := image.Decode(f)
+	return logo, err
+}
+
+// Encode renders QR code of the content with given options to w.
+func (g *Generator) Encode(w io.Writer, content string, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if opts.Logo && g.logo == nil {
+		return ErrLogoNotFound
+	}
+	if opts.Logo {
+		opts.Level = qrcode.Highest // logo covers a part of the code, which has to be restored
+	}
+
+	code, err := qrcode.New(content, opts.Level)
+	if err != nil {
+		return err
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return writeSVG(w, modules, opts)
+	}
+
+	img, err := renderPNG(modules, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Logo {
+		drawLogo(img, g.logo, opts.Background)
+	}
+	return png.Encode(w, img)
+}
+
+// renderPNG draws modules scaled to the image size, with margin around them.
+func renderPNG(modules [][]bool, opts Options) (*image.NRGBA, error) {
+	total := len(modules) + 2*opts.Margin
+	if opts.Size < total {
+		return nil, ErrSizeTooSmall
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	for y := 0; y < opts.Size; y++ {
+		my := y*total/opts.Size - opts.Margin
+		if my < 0 || my >= len(modules) {
+			continue
+		}
+		for x := 0; x < opts.Size; x++ {
+			mx := x*total/opts.Size - opts.Margin
+			if mx >= 0 && mx < len(modules) && modules[my][mx] {
+				img.SetNRGBA(x, y, opts.Foreground)
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// drawLogo draws the logo scaled to fit logoScale of the code width in its center, on a background pad.
+func drawLogo(img *image.NRGBA, logo image.Image, background color.NRGBA) {
+	size := img.Bounds().Dx()
+	bounds := logo.Bounds()
+
+	maxSide := int(float64(size) * logoScale)
+	w, h := maxSide, maxSide
+	if bounds.Dx() > bounds.Dy() {
+		h = maxSide * bounds.Dy() / bounds.Dx()
+	} else {
+		w = maxSide * bounds.Dx() / bounds.Dy()
+	}
+	if w == 0 || h == 0 {
+		return
+	}
+
+	pad := maxSide / 10
+	x0, y0 := (size-w)/2, (size-h)/2
+	padRect := image.Rect(x0-pad, y0-pad, x0+w+pad, y0+h+pad)
+	draw.Draw(img, padRect, image.NewUniform(background), image.Point{}, draw.Over)
+
+	// nearest neighbor scaling is enough for small logos
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := logo.At(bounds.Min.X+x*bounds.Dx()/w, bounds.Min.Y+y*bounds.Dy()/h)
+			dst := img.NRGBAAt(x0+x, y0+y)
+			img.Set(x0+x, y0+y, over(c, dst))
+		}
+	}
+}
+
+// over composites color c over dst.
+func over(c color.Color, dst color.NRGBA) color.Color {
+	src := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if src.A == 0xff {
+		return src
+	}
+	a := uint32(src.A)
+	blend := func(s, d uint8) uint8 {
+		return uint8((uint32(s)*a + uint32(d)*(0xff-a)) / 0xff)
+	}
+	return color.NRGBA{R: blend(src.R, dst.R), G: blend(src.G, dst.G), B: blend(src.B, dst.B), A: 0xff}
+}
+
+// writeSVG writes modules as SVG path of unit squares, with margin around them.
+func writeSVG(w io.Writer, modules [][]bool, opts Options) error {
+	total := len(modules) + 2*opts.Margin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" %s/>`, total, total, svgFill(opts.Background))
+	fmt.Fprintf(&b, `<path %s d="`, svgFill(opts.Foreground))
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// svgFill returns SVG fill attributes of the color.
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return fill
+}
@@ -0,0 +1,110 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"github.com/skip2/go-qrcode"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{input: "000000", want: color.NRGBA{A: 0xff}},
+		{input: "#1a2B3c", want: color.NRGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}},
+		{input: "f0a", want: color.NRGBA{R: 0xff, G: 0x00, B: 0xaa, A: 0xff}},
+		{input: "11223380", want: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x80}},
+		{input: "", wantErr: true},
+		{input: "12345", wantErr: true},
+		{input: "zzzzzz", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseColor(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseColor(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]qrcode.RecoveryLevel{"l": qrcode.Low, "M": qrcode.Medium, "q": qrcode.High, "H": qrcode.Highest}
+	for input, want := range cases {
+		got, err := ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("X"); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("ParseLevel(X) error = %v, want %v", err, ErrInvalidLevel)
+	}
+}
+
+func TestGenerator_Encode(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+
+	cases := []struct {
+		name    string
+		logo    image.Image
+		modify  func(o *Options)
+		wantErr error
+	}{
+		{name: "png"},
+		{name: "png with logo", logo: logo, modify: func(o *Options) { o.Logo = true }},
+		{name: "svg", modify: func(o *Options) { o.Format = FormatSVG }},
+		{name: "invalid format", modify: func(o *Options) { o.Format = "gif" }, wantErr: ErrInvalidFormat},
+		{name: "invalid size", modify: func(o *Options) { o.Size = MaxSize + 1 }, wantErr: ErrInvalidSize},
+		{name: "invalid margin", modify: func(o *Options) { o.Margin = -1 }, wantErr: ErrInvalidMargin},
+		{name: "size too small", modify: func(o *Options) { o.Size = MinSize; o.Margin = MaxMargin; o.Level = qrcode.Highest }, wantErr: ErrSizeTooSmall},
+		{name: "logo not configured", modify: func(o *Options) { o.Logo = true }, wantErr: ErrLogoNotFound},
+		{name: "logo in svg", logo: logo, modify: func(o *Options) { o.Logo = true; o.Format = FormatSVG }, wantErr: ErrLogoSVG},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			if tc.modify != nil {
+				tc.modify(&opts)
+			}
+
+			var buf bytes.Buffer
+			err := New(tc.logo).Encode(&buf, "https://make.short/s/abcdef", opts)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Encode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+
+			if opts.Format == FormatSVG {
+				if !strings.HasPrefix(buf.String(), "<svg") || !strings.Contains(buf.String(), `width="256"`) {
+					t.Errorf("Encode() = %q, want svg of width 256", buf.String())
+				}
+				return
+			}
+
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("png.Decode() error: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != opts.Size || b.Dy() != opts.Size {
+				t.Errorf("image size = %v, want %d", b.Size(), opts.Size)
+			}
+		})
+	}
+}
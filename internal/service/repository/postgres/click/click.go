@@ -0,0 +1,160 @@
+package click
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+// BreakdownLimit is the maximum number of entries in every stats breakdown.
+const BreakdownLimit = 20
+
+type Postgres struct {
+	db *sqlx.DB
+}
+
+type Click struct {
+	ID           int64     `db:"id"`
+	UrlID        string    `db:"url_id"`
+	Referrer     string    `db:"referrer"`
+	ReferrerHost string    `db:"referrer_host"`
+	UserAgent    string    `db:"user_agent"`
+	Country      string    `db:"country"`
+	Browser      string    `db:"browser"`
+	OS           string    `db:"os"`
+	Device       string    `db:"device"`
+	Variant      string    `db:"variant"` // ID of the served url variant, empty if the url has no variants
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type Entry struct {
+	Value string `db:"value"`
+	Count int    `db:"count"`
+}
+
+// Bucket is a clicks count of time bucket, which starts at Start.
+// Start is a wall clock time of the bucket in the time zone it was requested.
+type Bucket struct {
+	Start time.Time `db:"bucket"`
+	Count int       `db:"count"`
+}
+
+type Stats struct {
+	Total     int
+	Referrers []Entry
+	Countries []Entry
+	Devices   []Entry
+	Browsers  []Entry
+	Variants  []Entry
+}
+
+// New returns a new instance of *Postgres.
+func New(db *sqlx.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// CreateMany saves click events of many urls in database at once, keeping their creation time.
+// Clicks of urls which do not exist are skipped.
+func (p *Postgres) CreateMany(ctx context.Context, clicks []Click) error {
+	n := len(clicks)
+	urlIDs, referrers, referrerHosts, userAgents := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+	countries, browsers, oses, devices := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+	variants, createdAt := make([]string, n), make([]string, n)
+	for i, click := range clicks {
+		urlIDs[i] = click.UrlID
+		referrers[i] = click.Referrer
+		referrerHosts[i] = click.ReferrerHost
+		userAgents[i] = click.UserAgent
+		countries[i] = click.Country
+		browsers[i] = click.Browser
+		oses[i] = click.OS
+		devices[i] = click.Device
+		variants[i] = click.Variant
+		createdAt[i] = click.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	query := `INSERT INTO clicks (url_id, referrer, referrer_host, user_agent, country, browser, os, device, variant, created_at)
+		SELECT v.url_id, v.referrer, left(v.referrer_host, 255), v.user_agent, v.country, v.browser, v.os, v.device, v.variant, v.created_at
+		FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[], $8::text[], $9::text[], $10::timestamptz[])
+			AS v(url_id, referrer, referrer_host, user_agent, country, browser, os, device, variant, created_at)
+		JOIN urls ON urls.id = v.url_id`
+
+	_, err := p.db.ExecContext(ctx, query,
+		pq.Array(urlIDs), pq.Array(referrers), pq.Array(referrerHosts), pq.Array(userAgents), pq.Array(countries),
+		pq.Array(browsers), pq.Array(oses), pq.Array(devices), pq.Array(variants), pq.Array(createdAt),
+	)
+
+	return err
+}
+
+// GetStats returns total clicks count of the url and its breakdowns by referrer, country, device, browser and variant.
+// Every breakdown is sorted by clicks count and contains at most BreakdownLimit entries.
+func (p *Postgres) GetStats(ctx context.Context, urlID string) (Stats, error) {
+	var stats Stats
+
+	query := "SELECT count(*) FROM clicks WHERE url_id = $1"
+
+	err := p.db.GetContext(ctx, &stats.Total, query, urlID)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	breakdowns := []struct {
+		dest   *[]Entry
+		column string
+		empty  string
+	}{
+		{&stats.Referrers, "referrer_host", "direct"},
+		{&stats.Countries, "country", "unknown"},
+		{&stats.Devices, "device", "unknown"},
+		{&stats.Browsers, "browser", "unknown"},
+		{&stats.Variants, "variant", "none"},
+	}
+
+	for _, b := range breakdowns {
+		*b.dest, err = p.getBreakdown(ctx, urlID, b.column, b.empty)
+		if err != nil {
+			return Stats{}, err
+		}
+	}
+
+	return stats, nil
+}
+
+// getBreakdown returns clicks count of the url grouped by given column. Empty values are replaced with emptyValue.
+// Column must never come from user input.
+func (p *Postgres) getBreakdown(ctx context.Context, urlID string, column string, emptyValue string) ([]Entry, error) {
+	entries := make([]Entry, 0)
+
+	query := fmt.Sprintf("SELECT COALESCE(NULLIF(%s, ''), $2) AS value, count(*) AS count FROM clicks WHERE url_id = $1 GROUP BY value ORDER BY count DESC, value LIMIT $3", column)
+
+	err := p.db.SelectContext(ctx, &entries, query, urlID, emptyValue, BreakdownLimit)
+
+	return entries, err
+}
+
+// GetTimeSeries returns clicks count of the url between from (inclusive) and to (exclusive) grouped by buckets.
+// Buckets are truncated to interval ("hour", "day" or "week") in given time zone; buckets without clicks are omitted.
+func (p *Postgres) GetTimeSeries(ctx context.Context, urlID string, interval string, timeZone string, from time.Time, to time.Time) ([]Bucket, error) {
+	buckets := make([]Bucket, 0)
+
+	query := "SELECT date_trunc($2, created_at AT TIME ZONE $3) AS bucket, count(*) AS count FROM clicks WHERE url_id = $1 AND created_at >= $4 AND created_at < $5 GROUP BY bucket ORDER BY bucket"
+
+	err := p.db.SelectContext(ctx, &buckets, query, urlID, interval, timeZone, from, to)
+
+	return buckets, err
+}
+
+// GetUserTimeSeries returns clicks count of all urls of the user between from (inclusive) and to (exclusive) grouped by buckets.
+// Buckets are truncated to interval ("hour", "day" or "week") in given time zone; buckets without clicks are omitted.
+func (p *Postgres) GetUserTimeSeries(ctx context.Context, userID string, interval string, timeZone string, from time.Time, to time.Time) ([]Bucket, error) {
+	buckets := make([]Bucket, 0)
+
+	query := "SELECT date_trunc($2, c.created_at AT TIME ZONE $3) AS bucket, count(*) AS count FROM clicks c JOIN urls u ON u.id = c.url_id WHERE u.user_id = $1 AND c.created_at >= $4 AND c.created_at < $5 GROUP BY bucket ORDER BY bucket"
+
+	err := p.db.SelectContext(ctx, &buckets, query, userID, interval, timeZone, from, to)
+
+	return buckets, err
+}
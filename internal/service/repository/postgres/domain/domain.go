@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"backend/internal/service/repository/postgres/pgerr"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// constraints describes constraints of domains, which violations are translated to errors of the package.
+var constraints = pgerr.Constraints{
+	"domains_verified_host_key": {Err: ErrDomainAlreadyExists, Field: "host"},
+	"domains_user_id_host_key":  {Err: ErrDomainAlreadyExists, Field: "host"},
+}
+
+type Postgres struct {
+	db *sqlx.DB
+}
+
+type Domain struct {
+	ID                string     `db:"id"`
+	UserID            string     `db:"user_id"`
+	Host              string     `db:"host"`
+	VerificationToken string     `db:"verification_token"`
+	VerifiedAt        *time.Time `db:"verified_at"`
+	CreatedAt         time.Time  `db:"created_at"`
+}
+
+// IsVerified reports whether the domain ownership is verified, so the domain can serve urls.
+func (d Domain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// New returns a new instance of *Postgres.
+func New(db *sqlx.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Create creates a new not verified domain of the user in database. Several users can claim the same host,
+// it's taken by the one who verifies it first.
+// If the user already has a domain with this host, the function will return an ErrDomainAlreadyExists.
+func (p *Postgres) Create(ctx context.Context, userID string, host string, verificationToken string) (Domain, error) {
+	var domain Domain
+
+	query := "INSERT INTO domains (user_id, host, verification_token) VALUES ($1, $2, $3) RETURNING *"
+
+	err := p.db.GetContext(ctx, &domain, query, userID, host, verificationToken)
+	if err != nil {
+		return Domain{}, pgerr.Translate(err, constraints)
+	}
+
+	return domain, nil
+}
+
+// GetByID returns a domain by its ID.
+// If the domain does not exist in database, the function will return an ErrDomainNotFound.
+func (p *Postgres) GetByID(ctx context.Context, id string) (Domain, error) {
+	var domain Domain
+
+	query := "SELECT * FROM domains WHERE id = $1"
+
+	err := p.db.GetContext(ctx, &domain, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, ErrDomainNotFound
+	}
+
+	return domain, err
+}
+
+// GetByUserAndHost returns a domain of the user by its host.
+// If the user has no domain with this host, the function will return an ErrDomainNotFound.
+func (p *Postgres) GetByUserAndHost(ctx context.Context, userID string, host string) (Domain, error) {
+	var domain Domain
+
+	query := "SELECT * FROM domains WHERE user_id = $1 AND host = $2"
+
+	err := p.db.GetContext(ctx, &domain, query, userID, host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, ErrDomainNotFound
+	}
+
+	return domain, err
+}
+
+// GetVerifiedByHost returns a verified domain by its host.
+// If there is no verified domain with this host, the function will return an ErrDomainNotFound.
+func (p *Postgres) GetVerifiedByHost(ctx context.Context, host string) (Domain, error) {
+	var domain Domain
+
+	query := "SELECT * FROM domains WHERE host = $1 AND verified_at IS NOT NULL"
+
+	err := p.db.GetContext(ctx, &domain, query, host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, ErrDomainNotFound
+	}
+
+	return domain, err
+}
+
+// GetListByUser returns all domains of the user.
+// If the user has no domains, the function will return just an empty array.
+func (p *Postgres) GetListByUser(ctx context.Context, userID string) ([]Domain, error) {
+	domains := make([]Domain, 0)
+
+	query := "SELECT * FROM domains WHERE user_id = $1 ORDER BY created_at"
+
+	err := p.db.SelectContext(ctx, &domains, query, userID)
+
+	return domains, err
+}
+
+// SetVerified marks the domain as verified and returns it. Not verified claims of its host by other users are deleted.
+// If the domain does not exist in database, the function will return an ErrDomainNotFound.
+// If the host is already verified by another user, the function will return an ErrDomainAlreadyExists.
+func (p *Postgres) SetVerified(ctx context.Context, id string) (Domain, error) {
+	var domain Domain
+
+	query := `WITH verified AS (
+			UPDATE domains SET verified_at = COALESCE(verified_at, now()) WHERE id = $1 RETURNING *
+		), claims AS (
+			DELETE FROM domains WHERE host = (SELECT host FROM verified) AND verified_at IS NULL AND id <> $1
+		)
+		SELECT * FROM verified`
+
+	err := p.db.GetContext(ctx, &domain, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, ErrDomainNotFound
+	}
+	if err != nil {
+		return Domain{}, pgerr.Translate(err, constraints)
+	}
+
+	return domain, nil
+}
+
+// Delete deletes a domain with all its urls from database.
+// If the domain does not exist in database, the function will return an ErrDomainNotFound.
+func (p *Postgres) Delete(ctx context.Context, id string) error {
+	query := "DELETE FROM domains WHERE id = $1"
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrDomainNotFound
+	}
+
+	return nil
+}
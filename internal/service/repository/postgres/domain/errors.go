@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+var (
+	ErrDomainNotFound      = errors.New("repo.domain: domain not found")
+	ErrDomainAlreadyExists = errors.New("repo.domain: domain already exists")
+)
+
+func IsErrDomainNotFound(err error) bool {
+	return errors.Is(err, ErrDomainNotFound)
+}
+
+func IsErrDomainAlreadyExists(err error) bool {
+	return errors.Is(err, ErrDomainAlreadyExists)
+}
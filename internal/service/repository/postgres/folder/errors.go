@@ -0,0 +1,26 @@
+package folder
+
+import "errors"
+
+var (
+	ErrFolderNotFound      = errors.New("repo.folder: folder not found")
+	ErrFolderAlreadyExists = errors.New("repo.folder: folder already exists")
+	ErrParentNotFound      = errors.New("repo.folder: parent folder not found")
+	ErrCyclicMove          = errors.New("repo.folder: folder can't be moved into its subtree")
+)
+
+func IsErrFolderNotFound(err error) bool {
+	return errors.Is(err, ErrFolderNotFound)
+}
+
+func IsErrFolderAlreadyExists(err error) bool {
+	return errors.Is(err, ErrFolderAlreadyExists)
+}
+
+func IsErrParentNotFound(err error) bool {
+	return errors.Is(err, ErrParentNotFound)
+}
+
+func IsErrCyclicMove(err error) bool {
+	return errors.Is(err, ErrCyclicMove)
+}
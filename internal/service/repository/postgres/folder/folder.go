@@ -0,0 +1,167 @@
+package folder
+
+import (
+	"backend/internal/service/repository/postgres/pgerr"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+// constraints describes constraints of folders, which violations are translated to errors of the package.
+var constraints = pgerr.Constraints{
+	"folders_user_id_name_key":   {Err: ErrFolderAlreadyExists, Field: "name"},
+	"folders_parent_id_name_key": {Err: ErrFolderAlreadyExists, Field: "name"},
+	"folders_parent_id_fkey":     {Err: ErrParentNotFound, Field: "parent_id"},
+}
+
+type Postgres struct {
+	db *sqlx.DB
+}
+
+type Folder struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	ParentID  *string   `db:"parent_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	Urls      int       `db:"urls"` // number of urls right in the folder, selected only in lists
+}
+
+// DTO contains folder fields to update. Nil fields aren't updated, empty ParentID moves the folder to the top level.
+type DTO struct {
+	Name     *string `db:"name"`
+	ParentID *string `db:"parent_id"`
+}
+
+// New returns a new instance of *Postgres.
+func New(db *sqlx.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Create creates a new folder of the user in database. Empty parentID creates a top-level folder.
+// If the parent already has a folder with this name, the function will return an ErrFolderAlreadyExists.
+// If the parent folder does not exist, the function will return an ErrParentNotFound.
+func (p *Postgres) Create(ctx context.Context, userID string, parentID string, name string) (Folder, error) {
+	var folder Folder
+
+	query := "INSERT INTO folders (user_id, parent_id, name) VALUES ($1, NULLIF($2, '')::uuid, $3) RETURNING *"
+
+	err := p.db.GetContext(ctx, &folder, query, userID, parentID, name)
+	if err != nil {
+		return Folder{}, pgerr.Translate(err, constraints)
+	}
+
+	return folder, nil
+}
+
+// GetByID returns a folder by its ID.
+// If the folder does not exist in database, the function will return an ErrFolderNotFound.
+func (p *Postgres) GetByID(ctx context.Context, id string) (Folder, error) {
+	var folder Folder
+
+	query := "SELECT * FROM folders WHERE id = $1"
+
+	err := p.db.GetContext(ctx, &folder, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Folder{}, ErrFolderNotFound
+	}
+
+	return folder, err
+}
+
+// GetListByUser returns all folders of the user with numbers of their urls, sorted by name.
+// Folders are returned as a flat list, the hierarchy is built by their parent IDs.
+// If the user has no folders, the function will return just an empty array.
+func (p *Postgres) GetListByUser(ctx context.Context, userID string) ([]Folder, error) {
+	folders := make([]Folder, 0)
+
+	query := "SELECT folders.*, (SELECT count(*) FROM urls WHERE urls.folder_id = folders.id) AS urls FROM folders WHERE user_id = $1 ORDER BY name, id"
+
+	err := p.db.SelectContext(ctx, &folders, query, userID)
+
+	return folders, err
+}
+
+// Update renames or moves a folder and returns it.
+// If the folder does not exist in database, the function will return an ErrFolderNotFound.
+// If the parent already has a folder with this name, the function will return an ErrFolderAlreadyExists.
+// If the parent folder does not exist, the function will return an ErrParentNotFound, and if the parent is
+// the folder itself or one of its subfolders at any depth, an ErrCyclicMove.
+// Folders of the user are locked while the folder is moved, so concurrent moves can't create a cycle.
+func (p *Postgres) Update(ctx context.Context, id string, dto DTO) (Folder, error) {
+	var folder Folder
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Folder{}, err
+	}
+	defer tx.Rollback()
+
+	if dto.ParentID != nil && *dto.ParentID != "" {
+		query := "SELECT id FROM folders WHERE user_id = (SELECT user_id FROM folders WHERE id = $1) ORDER BY id FOR UPDATE"
+		if _, err = tx.ExecContext(ctx, query, id); err != nil {
+			return Folder{}, err
+		}
+
+		var cycle bool
+		query = "WITH RECURSIVE subtree AS (SELECT id FROM folders WHERE id = $1 UNION SELECT folders.id FROM folders JOIN subtree ON folders.parent_id = subtree.id) SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)"
+		if err = tx.GetContext(ctx, &cycle, query, id, *dto.ParentID); err != nil {
+			return Folder{}, err
+		}
+		if cycle {
+			return Folder{}, ErrCyclicMove
+		}
+	}
+
+	query := "UPDATE folders SET name = COALESCE($1, name), parent_id = CASE WHEN $2::text IS NULL THEN parent_id ELSE NULLIF($2, '')::uuid END WHERE id = $3 RETURNING *"
+
+	err = tx.GetContext(ctx, &folder, query, dto.Name, dto.ParentID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Folder{}, ErrFolderNotFound
+	}
+	if err != nil {
+		return Folder{}, pgerr.Translate(err, constraints)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Folder{}, err
+	}
+
+	return folder, nil
+}
+
+// Delete deletes a folder with all its subfolders from database, their urls are moved to no folder.
+// If the folder does not exist in database, the function will return an ErrFolderNotFound.
+func (p *Postgres) Delete(ctx context.Context, id string) error {
+	query := "DELETE FROM folders WHERE id = $1"
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrFolderNotFound
+	}
+
+	return nil
+}
+
+// MoveUrls moves many urls of the user to the folder at once and returns the number of moved urls.
+// Empty id moves urls to no folder. Urls of other users and urls which do not exist are skipped.
+func (p *Postgres) MoveUrls(ctx context.Context, id string, userID string, urlIDs []string) (int64, error) {
+	query := "UPDATE urls SET folder_id = NULLIF($1, '')::uuid WHERE id = ANY($2::uuid[]) AND user_id = $3"
+
+	res, err := p.db.ExecContext(ctx, query, id, pq.Array(urlIDs), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
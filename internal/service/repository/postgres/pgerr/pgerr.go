@@ -0,0 +1,140 @@
+package pgerr
+
+import (
+	"errors"
+	"github.com/lib/pq"
+	"regexp"
+	"strings"
+)
+
+// PostgreSQL error codes, which are translated.
+const (
+	UniqueViolation      = "23505"
+	ForeignKeyViolation  = "23503"
+	CheckViolation       = "23514"
+	SerializationFailure = "40001"
+)
+
+// PostgreSQL error classes of errors caused by values of rows.
+const (
+	dataException      = "22"
+	integrityViolation = "23"
+)
+
+var (
+	ErrUniqueViolation      = errors.New("pgerr: unique violation")
+	ErrForeignKeyViolation  = errors.New("pgerr: foreign key violation")
+	ErrCheckViolation       = errors.New("pgerr: check violation")
+	ErrSerializationFailure = errors.New("pgerr: serialization failure")
+)
+
+// kinds maps translated codes to their errors.
+var kinds = map[pq.ErrorCode]error{
+	UniqueViolation:      ErrUniqueViolation,
+	ForeignKeyViolation:  ErrForeignKeyViolation,
+	CheckViolation:       ErrCheckViolation,
+	SerializationFailure: ErrSerializationFailure,
+}
+
+// keyColumns extracts columns from details of violations, e.g. "Key (user_id, name)=(...) already exists.".
+var keyColumns = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// Constraint describes a constraint of a table: the error returned when it's violated and the field it protects.
+type Constraint struct {
+	Err   error
+	Field string
+}
+
+// Constraints maps constraint names to their descriptions.
+type Constraints map[string]Constraint
+
+// Error is a translated PostgreSQL error. It matches both its kind, e.g. ErrUniqueViolation,
+// and the error of the violated constraint with errors.Is.
+type Error struct {
+	Kind       error
+	Err        error  // error of the violated constraint, nil if the constraint isn't described
+	Constraint string // name of the violated constraint
+	Field      string // field protected by the constraint, or the last column of its key
+	cause      *pq.Error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error() + ": " + e.cause.Message
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	errs := []error{e.Kind, e.cause}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// Translate translates integrity violations and serialization failures of PostgreSQL to *Error,
+// taking errors and fields of violated constraints from constraints.
+// Other errors, including sql.ErrNoRows, are returned as is.
+func Translate(err error, constraints Constraints) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	kind, ok := kinds[pqErr.Code]
+	if !ok {
+		return err
+	}
+
+	e := &Error{
+		Kind:       kind,
+		Constraint: pqErr.Constraint,
+		Field:      pqErr.Column,
+		cause:      pqErr,
+	}
+
+	if m := keyColumns.FindStringSubmatch(pqErr.Detail); m != nil && e.Field == "" {
+		columns := strings.Split(m[1], ",")
+		e.Field = strings.TrimSpace(columns[len(columns)-1])
+	}
+
+	if c, ok := constraints[pqErr.Constraint]; ok {
+		e.Err = c.Err
+		if c.Field != "" {
+			e.Field = c.Field
+		}
+	}
+
+	return e
+}
+
+// Field returns the field protected by the violated constraint, or an empty string if err isn't a violation.
+func Field(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Field
+	}
+	return ""
+}
+
+// IsUniqueViolation reports whether err is a translated unique violation.
+func IsUniqueViolation(err error) bool {
+	return errors.Is(err, ErrUniqueViolation)
+}
+
+// IsForeignKeyViolation reports whether err is a translated foreign key violation.
+func IsForeignKeyViolation(err error) bool {
+	return errors.Is(err, ErrForeignKeyViolation)
+}
+
+// IsRowError reports whether err is caused by values of the written row: a data exception, e.g. too long value,
+// or an integrity violation. Such errors fail only the statement, so the row can be skipped by rolling back to a savepoint.
+func IsRowError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	class := pqErr.Code.Class()
+	return class == dataException || class == integrityViolation
+}
@@ -0,0 +1,127 @@
+package pgerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	errEmailTaken := errors.New("email taken")
+	constraints := Constraints{
+		"users_email_key":       {Err: errEmailTaken, Field: "email"},
+		"tags_user_id_name_key": {Err: errEmailTaken},
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantKind  error
+		wantErr   error
+		wantField string
+	}{
+		{
+			name:      "Described unique violation",
+			err:       &pq.Error{Code: UniqueViolation, Constraint: "users_email_key", Detail: "Key (email)=(a@b.c) already exists."},
+			wantKind:  ErrUniqueViolation,
+			wantErr:   errEmailTaken,
+			wantField: "email",
+		},
+		{
+			name:      "Field of composite key is its last column",
+			err:       &pq.Error{Code: UniqueViolation, Constraint: "tags_user_id_name_key", Detail: "Key (user_id, name)=(1, a) already exists."},
+			wantKind:  ErrUniqueViolation,
+			wantErr:   errEmailTaken,
+			wantField: "name",
+		},
+		{
+			name:      "Unknown constraint",
+			err:       &pq.Error{Code: UniqueViolation, Constraint: "users_username_key", Detail: "Key (username)=(a) already exists."},
+			wantKind:  ErrUniqueViolation,
+			wantField: "username",
+		},
+		{
+			name:      "Wrapped foreign key violation",
+			err:       fmt.Errorf("insert: %w", &pq.Error{Code: ForeignKeyViolation, Constraint: "urls_folder_id_fkey", Detail: `Key (folder_id)=(1) is not present in table "folders".`}),
+			wantKind:  ErrForeignKeyViolation,
+			wantField: "folder_id",
+		},
+		{
+			name:      "Check violation with column",
+			err:       &pq.Error{Code: CheckViolation, Column: "redirect_code"},
+			wantKind:  ErrCheckViolation,
+			wantField: "redirect_code",
+		},
+		{
+			name:     "Serialization failure",
+			err:      &pq.Error{Code: SerializationFailure},
+			wantKind: ErrSerializationFailure,
+		},
+		{
+			name: "Other PostgreSQL error",
+			err:  &pq.Error{Code: "42P01"},
+		},
+		{
+			name: "No rows",
+			err:  sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Translate(tt.err, constraints)
+
+			if tt.wantKind == nil {
+				if got != tt.err {
+					t.Fatalf("Translate() = %v, want the error as is", got)
+				}
+				return
+			}
+
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("Translate() = %v, want kind %v", got, tt.wantKind)
+			}
+			if tt.wantErr != nil && !errors.Is(got, tt.wantErr) {
+				t.Errorf("Translate() = %v, want %v", got, tt.wantErr)
+			}
+			var pqErr *pq.Error
+			if !errors.As(got, &pqErr) {
+				t.Errorf("Translate() = %v, doesn't keep the cause", got)
+			}
+			if field := Field(got); field != tt.wantField {
+				t.Errorf("Field() = %q, want %q", field, tt.wantField)
+			}
+		})
+	}
+
+	if Translate(nil, constraints) != nil {
+		t.Errorf("Translate(nil) != nil")
+	}
+}
+
+func TestIsRowError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Too long value", err: &pq.Error{Code: "22001"}, want: true},
+		{name: "Invalid text representation", err: &pq.Error{Code: "22P02"}, want: true},
+		{name: "Translated unique violation", err: Translate(&pq.Error{Code: UniqueViolation}, nil), want: true},
+		{name: "Not null violation", err: &pq.Error{Code: "23502"}, want: true},
+		{name: "Serialization failure", err: &pq.Error{Code: SerializationFailure}},
+		{name: "Undefined table", err: &pq.Error{Code: "42P01"}},
+		{name: "Connection error", err: errors.New("connection refused")},
+		{name: "No error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRowError(tt.err); got != tt.want {
+				t.Errorf("IsRowError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
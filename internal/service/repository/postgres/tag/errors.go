@@ -0,0 +1,16 @@
+package tag
+
+import "errors"
+
+var (
+	ErrTagNotFound      = errors.New("repo.tag: tag not found")
+	ErrTagAlreadyExists = errors.New("repo.tag: tag already exists")
+)
+
+func IsErrTagNotFound(err error) bool {
+	return errors.Is(err, ErrTagNotFound)
+}
+
+func IsErrTagAlreadyExists(err error) bool {
+	return errors.Is(err, ErrTagAlreadyExists)
+}
@@ -0,0 +1,140 @@
+package tag
+
+import (
+	"backend/internal/service/repository/postgres/pgerr"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+// constraints describes constraints of tags, which violations are translated to errors of the package.
+var constraints = pgerr.Constraints{
+	"tags_user_id_name_key": {Err: ErrTagAlreadyExists, Field: "name"},
+}
+
+type Postgres struct {
+	db *sqlx.DB
+}
+
+type Tag struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	Urls      int       `db:"urls"` // number of tagged urls, selected only in lists
+}
+
+// New returns a new instance of *Postgres.
+func New(db *sqlx.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Create creates a new tag of the user in database.
+// If the user already has a tag with this name, the function will return an ErrTagAlreadyExists.
+func (p *Postgres) Create(ctx context.Context, userID string, name string) (Tag, error) {
+	var tag Tag
+
+	query := "INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING *"
+
+	err := p.db.GetContext(ctx, &tag, query, userID, name)
+	if err != nil {
+		return Tag{}, pgerr.Translate(err, constraints)
+	}
+
+	return tag, nil
+}
+
+// GetByID returns a tag by its ID.
+// If the tag does not exist in database, the function will return an ErrTagNotFound.
+func (p *Postgres) GetByID(ctx context.Context, id string) (Tag, error) {
+	var tag Tag
+
+	query := "SELECT * FROM tags WHERE id = $1"
+
+	err := p.db.GetContext(ctx, &tag, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Tag{}, ErrTagNotFound
+	}
+
+	return tag, err
+}
+
+// GetListByUser returns all tags of the user with numbers of their urls, sorted by name.
+// If the user has no tags, the function will return just an empty array.
+func (p *Postgres) GetListByUser(ctx context.Context, userID string) ([]Tag, error) {
+	tags := make([]Tag, 0)
+
+	query := "SELECT tags.*, (SELECT count(*) FROM url_tags WHERE url_tags.tag_id = tags.id) AS urls FROM tags WHERE user_id = $1 ORDER BY name"
+
+	err := p.db.SelectContext(ctx, &tags, query, userID)
+
+	return tags, err
+}
+
+// Rename renames a tag and returns it.
+// If the tag does not exist in database, the function will return an ErrTagNotFound.
+// If the user already has a tag with this name, the function will return an ErrTagAlreadyExists.
+func (p *Postgres) Rename(ctx context.Context, id string, name string) (Tag, error) {
+	var tag Tag
+
+	query := "UPDATE tags SET name = $1 WHERE id = $2 RETURNING *"
+
+	err := p.db.GetContext(ctx, &tag, query, name, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Tag{}, ErrTagNotFound
+	}
+	if err != nil {
+		return Tag{}, pgerr.Translate(err, constraints)
+	}
+
+	return tag, nil
+}
+
+// Delete deletes a tag from database, urls lose the tag.
+// If the tag does not exist in database, the function will return an ErrTagNotFound.
+func (p *Postgres) Delete(ctx context.Context, id string) error {
+	query := "DELETE FROM tags WHERE id = $1"
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// AddUrls tags many urls of the user at once and returns the number of newly tagged urls.
+// Urls of other users, urls which do not exist and already tagged urls are skipped.
+func (p *Postgres) AddUrls(ctx context.Context, id string, userID string, urlIDs []string) (int64, error) {
+	query := "INSERT INTO url_tags (url_id, tag_id) SELECT urls.id, $1 FROM urls WHERE urls.id = ANY($2::uuid[]) AND urls.user_id = $3 ON CONFLICT DO NOTHING"
+
+	res, err := p.db.ExecContext(ctx, query, id, pq.Array(urlIDs), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// RemoveUrls removes the tag from many urls at once and returns the number of untagged urls.
+// Urls without the tag are skipped.
+func (p *Postgres) RemoveUrls(ctx context.Context, id string, urlIDs []string) (int64, error) {
+	query := "DELETE FROM url_tags WHERE tag_id = $1 AND url_id = ANY($2::uuid[])"
+
+	res, err := p.db.ExecContext(ctx, query, id, pq.Array(urlIDs))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -5,6 +5,9 @@ import "errors"
 var (
 	ErrShortUrlAlreadyExists = errors.New("repo.url: short url already exists")
 	ErrUrlNotFound           = errors.New("repo.url: url not found")
+	ErrFolderNotFound        = errors.New("repo.url: folder not found")
+	ErrDomainNotFound        = errors.New("repo.url: domain not found")
+	ErrRedirectsBudgetSpent  = errors.New("repo.url: redirects budget is spent")
 )
 
 func IsErrShortUrlAlreadyExists(err error) bool {
@@ -14,3 +17,15 @@ func IsErrShortUrlAlreadyExists(err error) bool {
 func IsErrUrlNotFound(err error) bool {
 	return errors.Is(err, ErrUrlNotFound)
 }
+
+func IsErrFolderNotFound(err error) bool {
+	return errors.Is(err, ErrFolderNotFound)
+}
+
+func IsErrDomainNotFound(err error) bool {
+	return errors.Is(err, ErrDomainNotFound)
+}
+
+func IsErrRedirectsBudgetSpent(err error) bool {
+	return errors.Is(err, ErrRedirectsBudgetSpent)
+}
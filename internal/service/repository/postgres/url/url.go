@@ -1,29 +1,129 @@
 package url
 
 import (
+	"backend/internal/service/repository/postgres/pgerr"
+	"backend/internal/service/rules"
+	"backend/internal/service/variants"
 	"context"
 	"database/sql"
 	"errors"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"sort"
 	"time"
 )
 
+// constraints describes constraints of urls, which violations are translated to errors of the package.
+var constraints = pgerr.Constraints{
+	"urls_short_url_key":           {Err: ErrShortUrlAlreadyExists, Field: "alias"},
+	"urls_domain_id_short_url_key": {Err: ErrShortUrlAlreadyExists, Field: "alias"},
+	"urls_folder_id_fkey":          {Err: ErrFolderNotFound, Field: "folder_id"},
+	"urls_domain_id_fkey":          {Err: ErrDomainNotFound, Field: "domain_id"},
+}
+
+const (
+	// DefaultRedirectCode is a redirect status code of urls, which don't set their own.
+	DefaultRedirectCode = 308
+	// MaxLongURLLength is the maximum length of long url in bytes, which can be stored in database.
+	MaxLongURLLength = 2048
+)
+
 type Postgres struct {
 	db *sqlx.DB
 }
 
 type URL struct {
-	ID        string    `db:"id"`
-	UserID    *string   `db:"user_id"`
-	LongURL   string    `db:"long_url"`
-	ShortURL  string    `db:"short_url"`
-	Redirects int       `db:"redirects"`
-	CreatedAt time.Time `db:"created_at"`
+	ID               string         `db:"id"`
+	UserID           *string        `db:"user_id"`
+	LongURL          string         `db:"long_url"`
+	ShortURL         string         `db:"short_url"`
+	Redirects        int            `db:"redirects"`
+	CreatedAt        time.Time      `db:"created_at"`
+	ExpiresAt        *time.Time     `db:"expires_at"`
+	MaxRedirects     *int           `db:"max_redirects"`
+	PasswordHash     *string        `db:"password_hash"`
+	DomainID         *string        `db:"domain_id"`
+	Domain           *string        `db:"domain"` // host of the domain, selected only with join of domains
+	RedirectCode     int            `db:"redirect_code"`
+	CacheControl     string         `db:"cache_control"`
+	ReferrerPolicy   string         `db:"referrer_policy"`
+	RobotsTag        string         `db:"robots_tag"`
+	QueryPassthrough string         `db:"query_passthrough"`
+	Rules            rules.List     `db:"rules"`
+	Variants         variants.List  `db:"variants"`
+	VariantsSticky   bool           `db:"variants_sticky"` // visitor gets the same variant on every visit
+	FolderID         *string        `db:"folder_id"`
+	Title            string         `db:"title"`
+	Notes            string         `db:"notes"`
+	Tags             pq.StringArray `db:"tags"` // names of url tags, selected only in lists and exports of user urls
 }
 
+// DTO contains url fields to create or update.
+// PasswordHash set to an empty string removes the password of the url on update.
+// DomainID, Redirects and CreatedAt are set on create only; empty DomainID means the default domain,
+// nil CreatedAt means the current time. Redirects and CreatedAt preserve history of imported urls.
+// Nil redirect and passthrough options are set to defaults on create and aren't updated on update.
+// Nil title and notes are empty on create and aren't updated on update.
+// Nil or empty FolderID means no folder on create, nil FolderID isn't updated and empty one moves the url to no folder on update.
+// ClearExpiresAt and ClearMaxRedirects remove expiration date and redirects budget on update, they're ignored on create.
 type DTO struct {
-	LongURL  string `db:"long_url"`
-	ShortURL string `db:"short_url"`
+	LongURL          string     `db:"long_url"`
+	ShortURL         string     `db:"short_url"`
+	ExpiresAt        *time.Time `db:"expires_at"`
+	MaxRedirects     *int       `db:"max_redirects"`
+	PasswordHash     *string    `db:"password_hash"`
+	DomainID         string     `db:"domain_id"`
+	Redirects        int        `db:"redirects"`
+	CreatedAt        *time.Time `db:"created_at"`
+	RedirectCode     *int       `db:"redirect_code"`
+	CacheControl     *string    `db:"cache_control"`
+	ReferrerPolicy   *string    `db:"referrer_policy"`
+	RobotsTag        *string    `db:"robots_tag"`
+	QueryPassthrough *string    `db:"query_passthrough"`
+	FolderID         *string    `db:"folder_id"`
+	Title            *string    `db:"title"`
+	Notes            *string    `db:"notes"`
+
+	ClearExpiresAt    bool
+	ClearMaxRedirects bool
+}
+
+// BatchResult is a result of creating one url of the batch: ID of the created url or an error of the row.
+type BatchResult struct {
+	ID  string
+	Err error
+}
+
+// GetDomainID returns ID of the url domain, or an empty string for the default domain.
+func (u URL) GetDomainID() string {
+	if u.DomainID == nil {
+		return ""
+	}
+	return *u.DomainID
+}
+
+// GetRedirectCode returns redirect status code of the url.
+func (u URL) GetRedirectCode() int {
+	if u.RedirectCode == 0 {
+		return DefaultRedirectCode
+	}
+	return u.RedirectCode
+}
+
+// IsPasswordProtected reports whether the url requires a password before redirect.
+func (u URL) IsPasswordProtected() bool {
+	return u.PasswordHash != nil
+}
+
+// IsExpired reports whether the url is no longer available for redirects,
+// either because its expiration date has passed or because its redirects budget is spent.
+// Redirects of urls with budget are counted synchronously by SpendRedirect, which is the final check of the budget.
+func (u URL) IsExpired(now time.Time) bool {
+	if u.ExpiresAt != nil && !now.Before(*u.ExpiresAt) {
+		return true
+	}
+
+	return u.MaxRedirects != nil && u.Redirects >= *u.MaxRedirects
 }
 
 // New returns a new instance of *Postgres.
@@ -31,27 +131,73 @@ func New(db *sqlx.DB) *Postgres {
 	return &Postgres{db: db}
 }
 
-// Create creates a new url in database. If url with provided short url already exists,
-// function will return an ErrShortUrlAlreadyExists.
-func (p *Postgres) Create(ctx context.Context, userID string, longUrl string, shortUrl string) (string, error) {
+// Create creates a new url in database. If userID is empty, url won't be assigned to any user.
+// If url with provided short url already exists, function will return an ErrShortUrlAlreadyExists.
+// If the folder or the domain of url does not exist, function will return an ErrFolderNotFound or an ErrDomainNotFound.
+func (p *Postgres) Create(ctx context.Context, userID string, dto DTO) (string, error) {
 	var id string
-	var err error
 
-	if userID == "" {
-		query := "INSERT INTO urls (long_url, short_url) values ($1, $2) RETURNING id"
-		err = p.db.GetContext(ctx, &id, query, longUrl, shortUrl)
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", ErrShortUrlAlreadyExists
+	query := "INSERT INTO urls (user_id, long_url, short_url, expires_at, max_redirects, password_hash, domain_id, redirects, created_at, redirect_code, cache_control, referrer_policy, robots_tag, query_passthrough, folder_id, title, notes) values (NULLIF($1, '')::uuid, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, $8, COALESCE($9, now()), COALESCE($10, 308), COALESCE($11, ''), COALESCE($12, ''), COALESCE($13, ''), COALESCE($14, 'off'), NULLIF($15, '')::uuid, COALESCE($16, ''), COALESCE($17, '')) RETURNING id"
+	err := p.db.GetContext(ctx, &id, query, userID, dto.LongURL, dto.ShortURL, dto.ExpiresAt, dto.MaxRedirects, dto.PasswordHash, dto.DomainID, dto.Redirects, dto.CreatedAt, dto.RedirectCode, dto.CacheControl, dto.ReferrerPolicy, dto.RobotsTag, dto.QueryPassthrough, dto.FolderID, dto.Title, dto.Notes)
+	if err != nil {
+		return "", pgerr.Translate(err, constraints)
+	}
+
+	return id, nil
+}
+
+// NextAliasNumber returns the next number of the sequence of generated aliases.
+func (p *Postgres) NextAliasNumber(ctx context.Context) (int64, error) {
+	var n int64
+
+	err := p.db.GetContext(ctx, &n, "SELECT nextval('urls_alias_seq')")
+
+	return n, err
+}
+
+// CreateBatch creates many urls in database in one transaction. If userID is empty, urls won't be assigned to any user.
+// Each url is created in its own savepoint, so the url with already existing short url gets an ErrShortUrlAlreadyExists
+// in its result without failing other urls, and so does the url with invalid values, e.g. too long, get its error.
+// Results are returned in the order of DTOs. Any other database error rolls back the whole batch and is returned as an error.
+func (p *Postgres) CreateBatch(ctx context.Context, userID string, dtos []DTO) ([]BatchResult, error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO urls (user_id, long_url, short_url, expires_at, max_redirects, password_hash, domain_id, redirects, created_at, redirect_code, cache_control, referrer_policy, robots_tag, query_passthrough, folder_id, title, notes) values (NULLIF($1, '')::uuid, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, $8, COALESCE($9, now()), COALESCE($10, 308), COALESCE($11, ''), COALESCE($12, ''), COALESCE($13, ''), COALESCE($14, 'off'), NULLIF($15, '')::uuid, COALESCE($16, ''), COALESCE($17, '')) RETURNING id"
+
+	results := make([]BatchResult, len(dtos))
+	for i, dto := range dtos {
+		if _, err = tx.ExecContext(ctx, "SAVEPOINT batch_row"); err != nil {
+			return nil, err
 		}
-	} else {
-		query := "INSERT INTO urls (user_id, long_url, short_url) values ($1, $2, $3) RETURNING id"
-		err = p.db.GetContext(ctx, &id, query, userID, longUrl, shortUrl)
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", ErrShortUrlAlreadyExists
+
+		err = tx.GetContext(ctx, &results[i].ID, query, userID, dto.LongURL, dto.ShortURL, dto.ExpiresAt, dto.MaxRedirects, dto.PasswordHash, dto.DomainID, dto.Redirects, dto.CreatedAt, dto.RedirectCode, dto.CacheControl, dto.ReferrerPolicy, dto.RobotsTag, dto.QueryPassthrough, dto.FolderID, dto.Title, dto.Notes)
+
+		err = pgerr.Translate(err, constraints)
+		if pgerr.IsRowError(err) {
+			results[i].Err = err
+			if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_row"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_row"); err != nil {
+			return nil, err
 		}
 	}
 
-	return id, err
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
 }
 
 // CreateWithoutUserReference creates a new url in database, without reference to any user.
@@ -61,11 +207,11 @@ func (p *Postgres) CreateWithoutUserReference(ctx context.Context, longUrl strin
 
 	query := "INSERT INTO urls (long_url, short_url) VALUES ($1, $2) RETURNING id"
 	err := p.db.GetContext(ctx, &id, query, longUrl, shortUrl)
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", ErrShortUrlAlreadyExists
+	if err != nil {
+		return "", pgerr.Translate(err, constraints)
 	}
 
-	return id, err
+	return id, nil
 }
 
 // GetByID returns an url by its ID.
@@ -83,14 +229,19 @@ func (p *Postgres) GetByID(ctx context.Context, id string) (URL, error) {
 	return url, err
 }
 
-// GetByShortUrl returns an url by its short url.
+// GetByShortUrl returns an url by its short url on the domain. Empty domainID means the default domain.
 // If the url does not exist in database, the function will return an ErrUrlNotFound.
-func (p *Postgres) GetByShortUrl(ctx context.Context, shortUrl string) (URL, error) {
+func (p *Postgres) GetByShortUrl(ctx context.Context, domainID string, shortUrl string) (URL, error) {
 	var url URL
+	var err error
 
-	query := "SELECT * FROM urls WHERE short_url = $1"
-
-	err := p.db.GetContext(ctx, &url, query, shortUrl)
+	if domainID == "" {
+		query := "SELECT * FROM urls WHERE short_url = $1 AND domain_id IS NULL"
+		err = p.db.GetContext(ctx, &url, query, shortUrl)
+	} else {
+		query := "SELECT * FROM urls WHERE short_url = $1 AND domain_id = $2"
+		err = p.db.GetContext(ctx, &url, query, shortUrl, domainID)
+	}
 	if errors.Is(err, sql.ErrNoRows) {
 		return URL{}, ErrUrlNotFound
 	}
@@ -98,6 +249,23 @@ func (p *Postgres) GetByShortUrl(ctx context.Context, shortUrl string) (URL, err
 	return url, err
 }
 
+// GetTakenShortUrls returns the short urls of the list, which are taken on the domain, in one query.
+// Empty domainID means the default domain.
+func (p *Postgres) GetTakenShortUrls(ctx context.Context, domainID string, shortUrls []string) ([]string, error) {
+	taken := make([]string, 0)
+	var err error
+
+	if domainID == "" {
+		query := "SELECT short_url FROM urls WHERE short_url = ANY($1) AND domain_id IS NULL"
+		err = p.db.SelectContext(ctx, &taken, query, pq.Array(shortUrls))
+	} else {
+		query := "SELECT short_url FROM urls WHERE short_url = ANY($1) AND domain_id = $2"
+		err = p.db.SelectContext(ctx, &taken, query, pq.Array(shortUrls), domainID)
+	}
+
+	return taken, err
+}
+
 // IncrementRedirectsCounter increments url's redirects counter in database.
 // If the url does not exist, the function wil return an ErrUrlNotFound.
 func (p *Postgres) IncrementRedirectsCounter(ctx context.Context, id string) error {
@@ -120,17 +288,93 @@ func (p *Postgres) IncrementRedirectsCounter(ctx context.Context, id string) err
 	return nil
 }
 
+// SpendRedirect increments url's redirects counter in database, if its redirects budget is not spent yet.
+// The budget is checked and spent by one statement, so concurrent redirects can't exceed it.
+// If the budget is spent or the url does not exist, the function will return an ErrRedirectsBudgetSpent.
+func (p *Postgres) SpendRedirect(ctx context.Context, id string) error {
+	query := "UPDATE urls SET redirects = redirects + 1 WHERE id = $1 AND (max_redirects IS NULL OR redirects < max_redirects)"
+
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRedirectsBudgetSpent
+	}
+
+	return nil
+}
+
+// IncrementRedirectsCounters increments redirects counters of many urls in database at once.
+// Increments are mapped by url ID; urls which do not exist are skipped.
+func (p *Postgres) IncrementRedirectsCounters(ctx context.Context, increments map[string]int) error {
+	ids := make([]string, 0, len(increments))
+	for id := range increments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // lock rows in the same order on every call
+
+	counts := make([]int64, len(ids))
+	for i, id := range ids {
+		counts[i] = int64(increments[id])
+	}
+
+	query := "UPDATE urls SET redirects = urls.redirects + v.n FROM unnest($1::uuid[], $2::int[]) AS v(id, n) WHERE urls.id = v.id"
+
+	_, err := p.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(counts))
+
+	return err
+}
+
 // Update updates an url in database. If url with provided ID does not exist,
-// the function will return an ErrUrlNotFound.
-// If some fields of DTO are empty, they won't be updated.
+// the function will return an ErrUrlNotFound. If the new short url is taken, the function will return
+// an ErrShortUrlAlreadyExists, and if the new folder does not exist, an ErrFolderNotFound.
+// If some fields of DTO are empty, they won't be updated, expiration date and redirects budget are removed by clear flags.
 func (p *Postgres) Update(ctx context.Context, id string, dto DTO) (URL, error) {
 	var url URL
 
-	query := "UPDATE urls SET short_url = CASE WHEN $1::varchar(10) IS NOT NULL AND $1 <> '' THEN $1 ELSE short_url END, long_url = CASE WHEN $2::varchar(2048) IS NOT NULL AND $2 <> '' THEN $2 ELSE long_url END WHERE id = $3 RETURNING *"
+	query := "UPDATE urls SET short_url = CASE WHEN $1::varchar(20) IS NOT NULL AND $1 <> '' THEN $1 ELSE short_url END, long_url = CASE WHEN $2::varchar(2048) IS NOT NULL AND $2 <> '' THEN $2 ELSE long_url END, expires_at = CASE WHEN $15 THEN NULL ELSE COALESCE($3, expires_at) END, max_redirects = CASE WHEN $16 THEN NULL ELSE COALESCE($4, max_redirects) END, password_hash = CASE WHEN $5::varchar(255) IS NULL THEN password_hash ELSE NULLIF($5, '') END, redirect_code = COALESCE($6, redirect_code), cache_control = COALESCE($7, cache_control), referrer_policy = COALESCE($8, referrer_policy), robots_tag = COALESCE($9, robots_tag), query_passthrough = COALESCE($10, query_passthrough), folder_id = CASE WHEN $11::text IS NULL THEN folder_id ELSE NULLIF($11, '')::uuid END, title = COALESCE($12, title), notes = COALESCE($13, notes) WHERE id = $14 RETURNING *"
+
+	err := p.db.GetContext(ctx, &url, query, dto.ShortURL, dto.LongURL, dto.ExpiresAt, dto.MaxRedirects, dto.PasswordHash, dto.RedirectCode, dto.CacheControl, dto.ReferrerPolicy, dto.RobotsTag, dto.QueryPassthrough, dto.FolderID, dto.Title, dto.Notes, id, dto.ClearExpiresAt, dto.ClearMaxRedirects)
+	if errors.Is(err, sql.ErrNoRows) {
+		return URL{}, ErrUrlNotFound
+	}
+	if err != nil {
+		return URL{}, pgerr.Translate(err, constraints)
+	}
+
+	return url, nil
+}
+
+// SetRules replaces redirect rules of an url in database. If url with provided ID does not exist,
+// the function will return an ErrUrlNotFound.
+func (p *Postgres) SetRules(ctx context.Context, id string, list rules.List) (URL, error) {
+	var url URL
+
+	query := "UPDATE urls SET rules = $1 WHERE id = $2 RETURNING *"
+
+	err := p.db.GetContext(ctx, &url, query, list, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return URL{}, ErrUrlNotFound
+	}
+
+	return url, err
+}
+
+// SetVariants replaces destination variants of an url in database. If url with provided ID does not exist,
+// the function will return an ErrUrlNotFound.
+func (p *Postgres) SetVariants(ctx context.Context, id string, list variants.List, sticky bool) (URL, error) {
+	var url URL
 
-	row := p.db.QueryRowContext(ctx, query, dto.ShortURL, dto.LongURL, id)
+	query := "UPDATE urls SET variants = $1, variants_sticky = $2 WHERE id = $3 RETURNING *"
 
-	err := row.Scan(&url.ID, &url.UserID, &url.LongURL, &url.ShortURL, &url.Redirects, &url.CreatedAt)
+	err := p.db.GetContext(ctx, &url, query, list, sticky, id)
 	if errors.Is(err, sql.ErrNoRows) {
 		return URL{}, ErrUrlNotFound
 	}
@@ -1,14 +1,25 @@
 package user
 
 import (
+	"backend/internal/service/repository/postgres/pgerr"
 	"backend/internal/service/repository/postgres/url"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// constraints describes constraints of users, which violations are translated to errors of the package.
+var constraints = pgerr.Constraints{
+	"users_email_key":       {Err: ErrUserAlreadyExists, Field: "email"},
+	"users_username_key":    {Err: ErrUserAlreadyExists, Field: "username"},
+	"users_telegram_id_key": {Err: ErrUserAlreadyExists, Field: "telegram_id"},
+}
+
 type Postgres struct {
 	db *sqlx.DB
 }
@@ -22,6 +33,79 @@ type User struct {
 	CreatedAt    time.Time `db:"created_at"`
 }
 
+// UrlFilter narrows the list of user urls. Empty fields don't filter.
+type UrlFilter struct {
+	Tag          string // name of url tag
+	FolderID     string
+	Subfolders   bool // include urls of subfolders of FolderID at any depth
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time // exclusive
+	MinRedirects int
+}
+
+// Sort fields of user urls.
+const (
+	SortCreatedAt = "created_at"
+	SortRedirects = "redirects"
+	SortAlias     = "alias"
+)
+
+// sortColumns maps sort fields to columns and types of their keys in cursors.
+var sortColumns = map[string]struct{ column, cast string }{
+	SortCreatedAt: {column: "urls.created_at", cast: "timestamp"},
+	SortRedirects: {column: "urls.redirects", cast: "int"},
+	SortAlias:     {column: "urls.short_url", cast: "text"},
+}
+
+// searchDocument is a full-text document of url. Urls are indexed by the same expression in urls_search_idx.
+// Destinations are split into words by punctuation, so their paths are searchable too.
+const searchDocument = "setweight(to_tsvector('simple', urls.short_url), 'A') || setweight(to_tsvector('simple', urls.title), 'A') || setweight(to_tsvector('simple', urls.notes), 'B') || setweight(to_tsvector('simple', regexp_replace(urls.long_url, '[^[:alnum:]]+', ' ', 'g')), 'C')"
+
+// likeEscaper escapes wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// UrlPage selects a page of user urls. Urls are sorted by Sort field and then by ID, so the order is stable.
+// The page starts right after the url with AfterKey and AfterID, or from the beginning if AfterID is empty.
+type UrlPage struct {
+	Sort     string // one of Sort* fields
+	Desc     bool
+	Limit    int
+	AfterKey string
+	AfterID  string
+}
+
+// KeyOf returns the sort key of the url to continue the list after it.
+func (p UrlPage) KeyOf(u url.URL) string {
+	switch p.Sort {
+	case SortRedirects:
+		return strconv.Itoa(u.Redirects)
+	case SortAlias:
+		return u.ShortURL
+	default:
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// IsValidSort reports whether urls can be sorted by the field.
+func IsValidSort(sort string) bool {
+	_, ok := sortColumns[sort]
+	return ok
+}
+
+// IsValidKey reports whether the key returned by KeyOf can be a key of the sort field.
+func IsValidKey(sort string, key string) bool {
+	switch sort {
+	case SortCreatedAt:
+		_, err := time.Parse(time.RFC3339Nano, key)
+		return err == nil
+	case SortRedirects:
+		_, err := strconv.Atoi(key)
+		return err == nil
+	default:
+		return IsValidSort(sort)
+	}
+}
+
 type DTO struct {
 	Email        string  `db:"email"`
 	Username     string  `db:"username"`
@@ -35,27 +119,25 @@ func New(db *sqlx.DB) *Postgres {
 }
 
 // Create creates a new user in database.
-// If the user with unique fields already exists in database, the function will return an ErrUserAlreadyExists.
+// If the user with unique fields already exists in database, the function will return an ErrUserAlreadyExists,
+// the taken field is reported by pgerr.Field.
 func (p *Postgres) Create(ctx context.Context, email string, username string, passwordHash string) (string, error) {
 	var id string
 
 	query := "INSERT INTO users (email, username, password_hash) values ($1, $2, $3) RETURNING id"
-	row := p.db.QueryRowContext(ctx, query, email, username, passwordHash)
 
-	if row.Err() != nil {
-		return "", row.Err()
-	}
-
-	err := row.Scan(&id)
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", ErrUserAlreadyExists
+	err := p.db.GetContext(ctx, &id, query, email, username, passwordHash)
+	if err != nil {
+		return "", pgerr.Translate(err, constraints)
 	}
 
-	return id, err
+	return id, nil
 }
 
 // Update updates a user by his ID in database.
 // If the user does not exist in database, the function will return ErrUserNotExists.
+// If the new email, username or telegram ID is taken, the function will return an ErrUserAlreadyExists,
+// the taken field is reported by pgerr.Field.
 // If some fields of DTO are empty, they won't be updated.
 func (p *Postgres) Update(ctx context.Context, id string, dto DTO) (User, error) {
 	var user User
@@ -68,8 +150,11 @@ func (p *Postgres) Update(ctx context.Context, id string, dto DTO) (User, error)
 	if errors.Is(err, sql.ErrNoRows) {
 		return User{}, ErrUserNotExists
 	}
+	if err != nil {
+		return User{}, pgerr.Translate(err, constraints)
+	}
 
-	return user, err
+	return user, nil
 }
 
 // Delete deletes a user by his ID from database.
@@ -92,6 +177,28 @@ func (p *Postgres) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// GetUrlAliases gets aliases of all urls assigned to provided user ID, grouped by ID of their domain.
+// Aliases on the default domain are grouped by an empty string.
+func (p *Postgres) GetUrlAliases(ctx context.Context, id string) (map[string][]string, error) {
+	var rows []struct {
+		DomainID string `db:"domain_id"`
+		ShortURL string `db:"short_url"`
+	}
+
+	query := "SELECT COALESCE(domain_id::text, '') AS domain_id, short_url FROM urls WHERE user_id = $1"
+
+	if err := p.db.SelectContext(ctx, &rows, query, id); err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string][]string)
+	for _, row := range rows {
+		aliases[row.DomainID] = append(aliases[row.DomainID], row.ShortURL)
+	}
+
+	return aliases, nil
+}
+
 // GetByID gets a user from database by his ID, and return as User.
 // If the user does not exist in database, the function will return an ErrUserNotExists.
 func (p *Postgres) GetByID(ctx context.Context, id string) (User, error) {
@@ -137,14 +244,119 @@ func (p *Postgres) GetByCredentials(ctx context.Context, email string, passwordH
 	return user, err
 }
 
-// GetUrlsList gets all urls from database, that assigned to provided user ID.
+// GetUrlsList gets a page of urls from database, that assigned to provided user ID and match the filter, with their tags.
+// The function also reports whether there are more urls after the page.
 // If urls with this user ID do not exist in database, the function will return just an empty array.
-func (p *Postgres) GetUrlsList(ctx context.Context, id string) ([]url.URL, error) {
-	var urls []url.URL
+func (p *Postgres) GetUrlsList(ctx context.Context, id string, filter UrlFilter, page UrlPage) ([]url.URL, bool, error) {
+	urls := make([]url.URL, 0)
 
-	query := "SELECT * FROM urls WHERE user_id = $1"
+	sort, ok := sortColumns[page.Sort]
+	if !ok {
+		sort = sortColumns[SortCreatedAt]
+	}
+	order, compare := "ASC", ">"
+	if page.Desc {
+		order, compare = "DESC", "<"
+	}
+
+	where, args := urlsFilter(id, filter)
+	if page.AfterID != "" {
+		args = append(args, page.AfterKey, page.AfterID)
+		where += fmt.Sprintf(" AND (%s, urls.id) %s ($%d::%s, $%d::uuid)", sort.column, compare, len(args)-1, sort.cast, len(args))
+	}
+	args = append(args, page.Limit+1)
 
-	err := p.db.SelectContext(ctx, &urls, query, id)
+	query := fmt.Sprintf("SELECT urls.*, domains.host AS domain, ARRAY(SELECT tags.name FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE url_tags.url_id = urls.id ORDER BY tags.name) AS tags FROM urls LEFT JOIN domains ON domains.id = urls.domain_id WHERE %s ORDER BY %s %s, urls.id %s LIMIT $%d", where, sort.column, order, order, len(args))
+
+	if err := p.db.SelectContext(ctx, &urls, query, args...); err != nil {
+		return nil, false, err
+	}
+
+	if len(urls) > page.Limit {
+		return urls[:page.Limit], true, nil
+	}
+
+	return urls, false, nil
+}
+
+// SearchUrls finds urls assigned to provided user ID by words of their alias, destination, title and notes,
+// ranked by relevance. Aliases are also matched by any part and by similarity, so partial aliases are found too.
+// If no urls match the query, the function will return just an empty array.
+func (p *Postgres) SearchUrls(ctx context.Context, id string, query string, limit int) ([]url.URL, error) {
+	urls := make([]url.URL, 0)
+
+	q := fmt.Sprintf("SELECT urls.*, domains.host AS domain, ARRAY(SELECT tags.name FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE url_tags.url_id = urls.id ORDER BY tags.name) AS tags FROM urls LEFT JOIN domains ON domains.id = urls.domain_id, websearch_to_tsquery('simple', $2) AS query WHERE urls.user_id = $1 AND ((%[1]s) @@ query OR urls.short_url ILIKE $3 OR urls.short_url %% $2) ORDER BY ts_rank(%[1]s, query) + similarity(urls.short_url, $2) DESC, urls.created_at DESC, urls.id LIMIT $4", searchDocument)
+
+	err := p.db.SelectContext(ctx, &urls, q, id, query, "%"+likeEscaper.Replace(query)+"%", limit)
 
 	return urls, err
 }
+
+// CountUrls returns the number of urls assigned to provided user ID, that match the filter.
+func (p *Postgres) CountUrls(ctx context.Context, id string, filter UrlFilter) (int, error) {
+	var count int
+
+	where, args := urlsFilter(id, filter)
+	query := "SELECT count(*) FROM urls WHERE " + where
+
+	err := p.db.GetContext(ctx, &count, query, args...)
+
+	return count, err
+}
+
+// urlsFilter returns the condition selecting urls of the user, which match the filter, and its arguments.
+func urlsFilter(id string, filter UrlFilter) (string, []any) {
+	where := "urls.user_id = $1"
+	args := []any{id}
+
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE url_tags.url_id = urls.id AND tags.name = $%d)", len(args))
+	}
+	if filter.FolderID != "" && filter.Subfolders {
+		args = append(args, filter.FolderID)
+		where += fmt.Sprintf(" AND urls.folder_id IN (WITH RECURSIVE subtree AS (SELECT id FROM folders WHERE id = $%d UNION SELECT folders.id FROM folders JOIN subtree ON folders.parent_id = subtree.id) SELECT id FROM subtree)", len(args))
+	} else if filter.FolderID != "" {
+		args = append(args, filter.FolderID)
+		where += fmt.Sprintf(" AND urls.folder_id = $%d", len(args))
+	}
+	if filter.CreatedFrom != nil {
+		args = append(args, *filter.CreatedFrom)
+		where += fmt.Sprintf(" AND urls.created_at >= $%d", len(args))
+	}
+	if filter.CreatedTo != nil {
+		args = append(args, *filter.CreatedTo)
+		where += fmt.Sprintf(" AND urls.created_at < $%d", len(args))
+	}
+	if filter.MinRedirects > 0 {
+		args = append(args, filter.MinRedirects)
+		where += fmt.Sprintf(" AND urls.redirects >= $%d", len(args))
+	}
+
+	return where, args
+}
+
+// ExportUrls streams all urls assigned to provided user ID with their tags from database cursor, calling fn for every url
+// in order of creation. Urls are not loaded into memory at once. If fn returns an error, streaming stops
+// and the function returns this error.
+func (p *Postgres) ExportUrls(ctx context.Context, id string, fn func(url.URL) error) error {
+	query := "SELECT urls.*, domains.host AS domain, ARRAY(SELECT tags.name FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE url_tags.url_id = urls.id ORDER BY tags.name) AS tags FROM urls LEFT JOIN domains ON domains.id = urls.domain_id WHERE urls.user_id = $1 ORDER BY urls.created_at, urls.id"
+
+	rows, err := p.db.QueryxContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u url.URL
+		if err = rows.StructScan(&u); err != nil {
+			return err
+		}
+		if err = fn(u); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
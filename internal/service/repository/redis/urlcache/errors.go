@@ -0,0 +1,11 @@
+package urlcache
+
+import "errors"
+
+var (
+	ErrCacheMiss = errors.New("repo.urlcache: cache miss")
+)
+
+func IsErrCacheMiss(err error) bool {
+	return errors.Is(err, ErrCacheMiss)
+}
@@ -0,0 +1,134 @@
+package urlcache
+
+import (
+	"backend/internal/config"
+	"backend/internal/service/repository/postgres/url"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	urlKeyPrefix    = "url:alias:"
+	domainKeyPrefix = "url:domain:"
+
+	// notFoundValue is cached for aliases, which do not exist in database, and for hosts, which are not custom domains.
+	notFoundValue = "-"
+)
+
+type Redis struct {
+	client *redis.Client
+	config config.Cache
+}
+
+// New returns a new instance of *Redis.
+func New(client *redis.Client, cfg config.Cache) *Redis {
+	return &Redis{
+		client: client,
+		config: cfg,
+	}
+}
+
+// Get returns a cached url by its short url on the domain. Empty domainID means the default domain.
+// If the url is not cached, the function will return an ErrCacheMiss.
+// If the url is cached as not existing, the function will return an url.ErrUrlNotFound.
+func (r *Redis) Get(ctx context.Context, domainID string, shortUrl string) (url.URL, error) {
+	data, err := r.client.Get(ctx, urlKey(domainID, shortUrl)).Result()
+	if errors.Is(err, redis.Nil) {
+		return url.URL{}, ErrCacheMiss
+	}
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	if data == notFoundValue {
+		return url.URL{}, url.ErrUrlNotFound
+	}
+
+	var u url.URL
+	err = json.Unmarshal([]byte(data), &u)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	return u, nil
+}
+
+// Set caches an url by its short url and domain for configured TTL.
+func (r *Redis) Set(ctx context.Context, u url.URL) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	var domainID string
+	if u.DomainID != nil {
+		domainID = *u.DomainID
+	}
+
+	return r.client.Set(ctx, urlKey(domainID, u.ShortURL), data, r.config.TTL).Err()
+}
+
+// SetNotFound caches that an url with given short url does not exist on the domain, for configured negative TTL.
+func (r *Redis) SetNotFound(ctx context.Context, domainID string, shortUrl string) error {
+	return r.client.Set(ctx, urlKey(domainID, shortUrl), notFoundValue, r.config.NegativeTTL).Err()
+}
+
+// Delete invalidates cached urls (both existing and not existing) by their short urls on the domain.
+func (r *Redis) Delete(ctx context.Context, domainID string, shortUrls ...string) error {
+	keys := make([]string, 0, len(shortUrls))
+	for _, shortUrl := range shortUrls {
+		if shortUrl != "" {
+			keys = append(keys, urlKey(domainID, shortUrl))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// GetDomain returns a cached ID of verified custom domain by its host.
+// If the host is cached as not a custom domain, the function will return an empty string.
+// If the host is not cached, the function will return an ErrCacheMiss.
+func (r *Redis) GetDomain(ctx context.Context, host string) (string, error) {
+	data, err := r.client.Get(ctx, domainKey(host)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if data == notFoundValue {
+		return "", nil
+	}
+
+	return data, nil
+}
+
+// SetDomain caches an ID of verified custom domain by its host for configured TTL.
+// Empty domainID caches that the host is not a custom domain, for configured negative TTL.
+func (r *Redis) SetDomain(ctx context.Context, host string, domainID string) error {
+	if domainID == "" {
+		return r.client.Set(ctx, domainKey(host), notFoundValue, r.config.NegativeTTL).Err()
+	}
+	return r.client.Set(ctx, domainKey(host), domainID, r.config.TTL).Err()
+}
+
+// DeleteDomain invalidates a cached domain by its host.
+func (r *Redis) DeleteDomain(ctx context.Context, host string) error {
+	return r.client.Del(ctx, domainKey(host)).Err()
+}
+
+// urlKey returns a redis key of the short url on the domain.
+func urlKey(domainID string, shortUrl string) string {
+	return urlKeyPrefix + domainID + ":" + shortUrl
+}
+
+// domainKey returns a redis key of the domain host.
+func domainKey(host string) string {
+	return domainKeyPrefix + host
+}
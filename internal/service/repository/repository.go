@@ -2,13 +2,22 @@ package repository
 
 import (
 	"backend/internal/config"
+	"backend/internal/service/repository/postgres/click"
+	"backend/internal/service/repository/postgres/domain"
+	"backend/internal/service/repository/postgres/folder"
+	"backend/internal/service/repository/postgres/pgerr"
+	"backend/internal/service/repository/postgres/tag"
 	"backend/internal/service/repository/postgres/url"
 	"backend/internal/service/repository/postgres/user"
 	"backend/internal/service/repository/redis/session"
+	"backend/internal/service/repository/redis/urlcache"
+	"backend/internal/service/rules"
+	"backend/internal/service/variants"
 	"context"
 	"errors"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
+	"time"
 )
 
 type User interface {
@@ -16,20 +25,77 @@ type User interface {
 	GetByID(ctx context.Context, id string) (user.User, error)
 	GetByTelegramID(ctx context.Context, telegramID string) (user.User, error)
 	GetByCredentials(ctx context.Context, email string, passwordHash string) (user.User, error)
-	GetUrlsList(ctx context.Context, id string) ([]url.URL, error)
+	GetUrlsList(ctx context.Context, id string, filter user.UrlFilter, page user.UrlPage) ([]url.URL, bool, error)
+	CountUrls(ctx context.Context, id string, filter user.UrlFilter) (int, error)
+	SearchUrls(ctx context.Context, id string, query string, limit int) ([]url.URL, error)
+	ExportUrls(ctx context.Context, id string, fn func(url.URL) error) error
+	GetUrlAliases(ctx context.Context, id string) (map[string][]string, error)
 	Update(ctx context.Context, id string, dto user.DTO) (user.User, error)
 	Delete(ctx context.Context, id string) error
 }
 
 type Url interface {
-	Create(ctx context.Context, longUrl string, shortUrl string, userID string) (string, error)
+	Create(ctx context.Context, userID string, dto url.DTO) (string, error)
+	CreateBatch(ctx context.Context, userID string, dtos []url.DTO) ([]url.BatchResult, error)
+	NextAliasNumber(ctx context.Context) (int64, error)
 	GetByID(ctx context.Context, id string) (url.URL, error)
-	GetByShortUrl(ctx context.Context, shortUrl string) (url.URL, error)
+	GetByShortUrl(ctx context.Context, domainID string, shortUrl string) (url.URL, error)
+	GetTakenShortUrls(ctx context.Context, domainID string, shortUrls []string) ([]string, error)
 	IncrementRedirectsCounter(ctx context.Context, id string) error
+	SpendRedirect(ctx context.Context, id string) error
+	IncrementRedirectsCounters(ctx context.Context, increments map[string]int) error
 	Update(ctx context.Context, id string, dto url.DTO) (url.URL, error)
+	SetRules(ctx context.Context, id string, list rules.List) (url.URL, error)
+	SetVariants(ctx context.Context, id string, list variants.List, sticky bool) (url.URL, error)
 	Delete(ctx context.Context, id string) error
 }
 
+type Click interface {
+	CreateMany(ctx context.Context, clicks []click.Click) error
+	GetStats(ctx context.Context, urlID string) (click.Stats, error)
+	GetTimeSeries(ctx context.Context, urlID string, interval string, timeZone string, from time.Time, to time.Time) ([]click.Bucket, error)
+	GetUserTimeSeries(ctx context.Context, userID string, interval string, timeZone string, from time.Time, to time.Time) ([]click.Bucket, error)
+}
+
+type Domain interface {
+	Create(ctx context.Context, userID string, host string, verificationToken string) (domain.Domain, error)
+	GetByID(ctx context.Context, id string) (domain.Domain, error)
+	GetByUserAndHost(ctx context.Context, userID string, host string) (domain.Domain, error)
+	GetVerifiedByHost(ctx context.Context, host string) (domain.Domain, error)
+	GetListByUser(ctx context.Context, userID string) ([]domain.Domain, error)
+	SetVerified(ctx context.Context, id string) (domain.Domain, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Tag interface {
+	Create(ctx context.Context, userID string, name string) (tag.Tag, error)
+	GetByID(ctx context.Context, id string) (tag.Tag, error)
+	GetListByUser(ctx context.Context, userID string) ([]tag.Tag, error)
+	Rename(ctx context.Context, id string, name string) (tag.Tag, error)
+	Delete(ctx context.Context, id string) error
+	AddUrls(ctx context.Context, id string, userID string, urlIDs []string) (int64, error)
+	RemoveUrls(ctx context.Context, id string, urlIDs []string) (int64, error)
+}
+
+type Folder interface {
+	Create(ctx context.Context, userID string, parentID string, name string) (folder.Folder, error)
+	GetByID(ctx context.Context, id string) (folder.Folder, error)
+	GetListByUser(ctx context.Context, userID string) ([]folder.Folder, error)
+	Update(ctx context.Context, id string, dto folder.DTO) (folder.Folder, error)
+	Delete(ctx context.Context, id string) error
+	MoveUrls(ctx context.Context, id string, userID string, urlIDs []string) (int64, error)
+}
+
+type UrlCache interface {
+	Get(ctx context.Context, domainID string, shortUrl string) (url.URL, error)
+	Set(ctx context.Context, u url.URL) error
+	SetNotFound(ctx context.Context, domainID string, shortUrl string) error
+	Delete(ctx context.Context, domainID string, shortUrls ...string) error
+	GetDomain(ctx context.Context, host string) (string, error)
+	SetDomain(ctx context.Context, host string, domainID string) error
+	DeleteDomain(ctx context.Context, host string) error
+}
+
 type Session interface {
 	Create(ctx context.Context, refreshToken string, userID string, ip string, userAgent string) error
 	Close(ctx context.Context, refreshToken string) error
@@ -37,22 +103,47 @@ type Session interface {
 }
 
 type Repository struct {
-	User    *user.Postgres
-	Url     *url.Postgres
-	Session *session.Redis
+	User     *user.Postgres
+	Url      *url.Postgres
+	Click    *click.Postgres
+	Domain   *domain.Postgres
+	Tag      *tag.Postgres
+	Folder   *folder.Postgres
+	UrlCache *urlcache.Redis
+	Session  *session.Redis
 }
 
 func New(postgresDB *sqlx.DB, redisDB *redis.Client, cfg *config.Config) *Repository {
 	return &Repository{
-		User:    user.New(postgresDB),
-		Url:     url.New(postgresDB),
-		Session: session.New(redisDB, cfg),
+		User:     user.New(postgresDB),
+		Url:      url.New(postgresDB),
+		Click:    click.New(postgresDB),
+		Domain:   domain.New(postgresDB),
+		Tag:      tag.New(postgresDB),
+		Folder:   folder.New(postgresDB),
+		UrlCache: urlcache.New(redisDB, cfg.Cache),
+		Session:  session.New(redisDB, cfg),
 	}
 }
 
 var (
 	ErrURLNotFound            = errors.New("url not found")
-	ErrAliasAlreadyExists     = errors.New("alias already exists")
 	ErrUserNotFound           = errors.New("user not found")
 	ErrRefreshSessionNotFound = errors.New("refresh session not found")
 )
+
+// Errors of violated constraints. Postgres repositories translate violations to them with pgerr.Translate,
+// and pgerr.Field reports the conflicting field.
+var (
+	ErrAliasAlreadyExists   = url.ErrShortUrlAlreadyExists
+	ErrUserAlreadyExists    = user.ErrUserAlreadyExists
+	ErrDomainAlreadyExists  = domain.ErrDomainAlreadyExists
+	ErrTagAlreadyExists     = tag.ErrTagAlreadyExists
+	ErrFolderAlreadyExists  = folder.ErrFolderAlreadyExists
+	ErrSerializationFailure = pgerr.ErrSerializationFailure
+)
+
+// ConflictField returns the field, which value violated a constraint, or an empty string if err isn't a violation.
+func ConflictField(err error) string {
+	return pgerr.Field(err)
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"backend/internal/lib/useragent"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxRules is a maximum number of rules of one url.
+const MaxRules = 50
+
+var (
+	ErrTooManyRules   = fmt.Errorf("rules: url can't have more than %d rules", MaxRules)
+	ErrNoConditions   = errors.New("rules: rule must have at least one of countries, os, devices or browsers")
+	ErrInvalidCountry = errors.New("rules: country must be ISO 3166-1 alpha-2 code, e.g. US")
+	ErrUnknownOS      = errors.New("rules: unknown os, must be one of iOS, Android, Windows, ChromeOS, macOS, Linux")
+	ErrUnknownDevice  = errors.New("rules: unknown device, must be one of desktop, mobile, tablet, bot")
+	ErrUnknownBrowser = errors.New("rules: unknown browser")
+	ErrRuleNotFound   = errors.New("rules: rule not found")
+)
+
+// Rule redirects visitors matching all of its conditions to its url.
+// Values of one condition are alternatives, and empty condition matches any visitor.
+type Rule struct {
+	ID        string   `json:"id"`
+	Countries []string `json:"countries,omitempty"`
+	OS        []string `json:"os,omitempty"`
+	Devices   []string `json:"devices,omitempty"`
+	Browsers  []string `json:"browsers,omitempty"`
+	Url       string   `json:"url"`
+}
+
+// Visitor is a client following the short url.
+type Visitor struct {
+	Agent   useragent.Agent
+	Country string // ISO 3166-1 alpha-2 code, empty if unknown
+}
+
+// List is an ordered list of url rules, stored in database as a JSON array.
+type List []Rule
+
+// Normalize validates conditions of the rule and replaces their values with the canonical ones, e.g. "ios" with "iOS".
+func (r *Rule) Normalize() error {
+	if len(r.Countries) == 0 && len(r.OS) == 0 && len(r.Devices) == 0 && len(r.Browsers) == 0 {
+		return ErrNoConditions
+	}
+
+	for i, country := range r.Countries {
+		if !isCountryCode(country) {
+			return ErrInvalidCountry
+		}
+		r.Countries[i] = strings.ToUpper(country)
+	}
+
+	var ok bool
+	for i := range r.OS {
+		if r.OS[i], ok = useragent.CanonicalOS(r.OS[i]); !ok {
+			return ErrUnknownOS
+		}
+	}
+	for i := range r.Devices {
+		if r.Devices[i], ok = useragent.CanonicalDevice(r.Devices[i]); !ok {
+			return ErrUnknownDevice
+		}
+	}
+	for i := range r.Browsers {
+		if r.Browsers[i], ok = useragent.CanonicalBrowser(r.Browsers[i]); !ok {
+			return ErrUnknownBrowser
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the visitor matches all conditions of the rule.
+// Visitors of unknown country don't match rules with countries.
+func (r Rule) Matches(v Visitor) bool {
+	return matchesAny(r.Countries, v.Country) &&
+		matchesAny(r.OS, v.Agent.OS) &&
+		matchesAny(r.Devices, v.Agent.Device) &&
+		matchesAny(r.Browsers, v.Agent.Browser)
+}
+
+// Match returns the first rule of the list, which the visitor matches.
+func (l List) Match(v Visitor) (Rule, bool) {
+	for _, rule := range l {
+		if rule.Matches(v) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Remove returns the list without the rule with given ID.
+// If the rule does not exist, the function returns an ErrRuleNotFound.
+func (l List) Remove(id string) (List, error) {
+	for i, rule := range l {
+		if rule.ID == id {
+			return append(l[:i:i], l[i+1:]...), nil
+		}
+	}
+	return l, ErrRuleNotFound
+}
+
+// Value implements driver.Valuer, so the list is stored as a JSON array.
+func (l List) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner, so the list is read from a JSON array.
+func (l *List) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, l)
+	case string:
+		return json.Unmarshal([]byte(src), l)
+	default:
+		return fmt.Errorf("rules: can't scan %T into list", src)
+	}
+}
+
+// isCountryCode reports whether s is a code of two latin letters.
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !('a' <= s[i] && s[i] <= 'z' || 'A' <= s[i] && s[i] <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny reports whether value is one of values. Empty values match any value.
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
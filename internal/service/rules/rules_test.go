@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"backend/internal/lib/useragent"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestListMatch(t *testing.T) {
+	list := List{
+		{ID: "ios", OS: []string{"iOS"}, Url: "https://apps.apple.com/app/id1"},
+		{ID: "android", OS: []string{"Android"}, Devices: []string{useragent.DeviceMobile, useragent.DeviceTablet}, Url: "https://play.google.com/store/apps/details?id=app"},
+		{ID: "firefox", Browsers: []string{"Firefox"}, Url: "https://example.com/firefox"},
+		{ID: "dach", Countries: []string{"DE", "AT", "CH"}, Url: "https://example.de"},
+		{ID: "us-desktop", Countries: []string{"US"}, Devices: []string{useragent.DeviceDesktop}, Url: "https://example.com/us"},
+	}
+
+	tests := []struct {
+		name    string
+		agent   useragent.Agent
+		country string
+		wantID  string
+		wantHit bool
+	}{
+		{
+			name:    "iPhone",
+			agent:   useragent.Agent{Browser: "Safari", OS: "iOS", Device: useragent.DeviceMobile},
+			wantID:  "ios",
+			wantHit: true,
+		},
+		{
+			name:    "Android tablet",
+			agent:   useragent.Agent{Browser: "Chrome", OS: "Android", Device: useragent.DeviceTablet},
+			wantID:  "android",
+			wantHit: true,
+		},
+		{
+			name:    "Firefox on iPhone matches the first rule",
+			agent:   useragent.Agent{Browser: "Firefox", OS: "iOS", Device: useragent.DeviceMobile},
+			wantID:  "ios",
+			wantHit: true,
+		},
+		{
+			name:    "Firefox on Linux",
+			agent:   useragent.Agent{Browser: "Firefox", OS: "Linux", Device: useragent.DeviceDesktop},
+			wantID:  "firefox",
+			wantHit: true,
+		},
+		{
+			name:    "Chrome on Windows in Austria",
+			agent:   useragent.Agent{Browser: "Chrome", OS: "Windows", Device: useragent.DeviceDesktop},
+			country: "AT",
+			wantID:  "dach",
+			wantHit: true,
+		},
+		{
+			name:    "Chrome on Windows in US",
+			agent:   useragent.Agent{Browser: "Chrome", OS: "Windows", Device: useragent.DeviceDesktop},
+			country: "US",
+			wantID:  "us-desktop",
+			wantHit: true,
+		},
+		{
+			name:    "Chrome on Windows in France",
+			agent:   useragent.Agent{Browser: "Chrome", OS: "Windows", Device: useragent.DeviceDesktop},
+			country: "FR",
+		},
+		{
+			name:  "Chrome on Windows in unknown country",
+			agent: useragent.Agent{Browser: "Chrome", OS: "Windows", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:  "Unknown agent",
+			agent: useragent.Agent{Browser: useragent.Unknown, OS: useragent.Unknown, Device: useragent.Unknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := list.Match(Visitor{Agent: tt.agent, Country: tt.country})
+			if ok != tt.wantHit || rule.ID != tt.wantID {
+				t.Errorf("Match() = %q, %v, want %q, %v", rule.ID, ok, tt.wantID, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestRuleNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		want    Rule
+		wantErr error
+	}{
+		{
+			name: "Values are canonical",
+			rule: Rule{Countries: []string{"de", "Us"}, OS: []string{"ios", "ANDROID"}, Devices: []string{"Mobile"}, Browsers: []string{"samsung internet"}},
+			want: Rule{Countries: []string{"DE", "US"}, OS: []string{"iOS", "Android"}, Devices: []string{"mobile"}, Browsers: []string{"Samsung Internet"}},
+		},
+		{
+			name:    "Invalid country",
+			rule:    Rule{Countries: []string{"DEU"}},
+			wantErr: ErrInvalidCountry,
+		},
+		{
+			name:    "No conditions",
+			rule:    Rule{Url: "https://example.com"},
+			wantErr: ErrNoConditions,
+		},
+		{
+			name:    "Unknown OS",
+			rule:    Rule{OS: []string{"Symbian"}},
+			wantErr: ErrUnknownOS,
+		},
+		{
+			name:    "Unknown device",
+			rule:    Rule{Devices: []string{"watch"}},
+			wantErr: ErrUnknownDevice,
+		},
+		{
+			name:    "Unknown browser",
+			rule:    Rule{Browsers: []string{"Netscape"}},
+			wantErr: ErrUnknownBrowser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Normalize()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.rule, tt.want) {
+				t.Errorf("Normalize() = %+v, want %+v", tt.rule, tt.want)
+			}
+		})
+	}
+}
+
+func TestListScan(t *testing.T) {
+	list := List{{ID: "a", OS: []string{"iOS"}, Url: "https://example.com"}}
+
+	value, err := list.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var got List
+	if err = got.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, list) {
+		t.Errorf("Scan() = %+v, want %+v", got, list)
+	}
+
+	if value, _ = List(nil).Value(); string(value.([]byte)) != "[]" {
+		t.Errorf("Value() of nil list = %s, want []", value)
+	}
+}
+
+func TestListRemove(t *testing.T) {
+	list := List{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	got, err := list.Remove("b")
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, List{{ID: "a"}, {ID: "c"}}) {
+		t.Errorf("Remove() = %+v", got)
+	}
+	if !reflect.DeepEqual(list, List{{ID: "a"}, {ID: "b"}, {ID: "c"}}) {
+		t.Errorf("Remove() changed the original list: %+v", list)
+	}
+
+	if _, err = list.Remove("d"); !errors.Is(err, ErrRuleNotFound) {
+		t.Errorf("Remove() error = %v, want %v", err, ErrRuleNotFound)
+	}
+}
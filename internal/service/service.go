@@ -1,22 +1,45 @@
 package service
 
 import (
+	"backend/internal/service/alias"
+	"backend/internal/service/counter"
+	"backend/internal/service/destination"
+	"backend/internal/service/domain"
+	"backend/internal/service/geoip"
 	"backend/internal/service/hash"
+	"backend/internal/service/importer"
+	"backend/internal/service/qr"
 	"backend/internal/service/repository"
 	"backend/internal/service/token"
 )
 
 type Service struct {
-	Repository   *repository.Repository
-	TokenManager *token.Manager
-	Hasher       *hash.Hasher
+	Repository      *repository.Repository
+	TokenManager    *token.Manager
+	Hasher          *hash.Hasher
+	GeoIP           *geoip.Reader
+	RedirectCounter *counter.Counter
+	DomainVerifier  *domain.Verifier
+	Importer        *importer.Importer
+	QR              *qr.Generator
+	Alias           *alias.Allocator
+	AliasPolicy     *alias.Policy
+	Destination     *destination.Policy
 }
 
 // New returns a new instance of Service.
-func New(tokenManager *token.Manager, hasher *hash.Hasher, repo *repository.Repository) *Service {
+func New(tokenManager *token.Manager, hasher *hash.Hasher, repo *repository.Repository, geoIP *geoip.Reader, redirectCounter *counter.Counter, domainVerifier *domain.Verifier, qrGenerator *qr.Generator, aliasAllocator *alias.Allocator, aliasPolicy *alias.Policy, destinationPolicy *destination.Policy) *Service {
 	return &Service{
-		Repository:   repo,
-		TokenManager: tokenManager,
-		Hasher:       hasher,
+		Repository:      repo,
+		TokenManager:    tokenManager,
+		Hasher:          hasher,
+		GeoIP:           geoIP,
+		RedirectCounter: redirectCounter,
+		DomainVerifier:  domainVerifier,
+		Importer:        importer.New(repo.Url, destinationPolicy, importer.DefaultBatchSize),
+		QR:              qrGenerator,
+		Alias:           aliasAllocator,
+		AliasPolicy:     aliasPolicy,
+		Destination:     destinationPolicy,
 	}
 }
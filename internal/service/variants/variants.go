@@ -0,0 +1,150 @@
+package variants
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// MaxVariants is a maximum number of variants of one url.
+	MaxVariants = 10
+	// MaxWeight is a maximum weight of one variant.
+	MaxWeight = 1000
+	// MaxIDLength is a maximum length of variant ID.
+	MaxIDLength = 20
+)
+
+var (
+	ErrTooFewVariants  = errors.New("variants: url must have at least 2 variants")
+	ErrTooManyVariants = fmt.Errorf("variants: url can't have more than %d variants", MaxVariants)
+	ErrInvalidWeight   = fmt.Errorf("variants: weight must be between 1 and %d", MaxWeight)
+	ErrInvalidID       = fmt.Errorf("variants: id must be 1-%d latin letters, digits, '-' or '_'", MaxIDLength)
+	ErrDuplicateID     = errors.New("variants: ids of variants must be unique")
+)
+
+// Variant is one of destinations of the url, which is served to the share of visitors proportional to its weight.
+type Variant struct {
+	ID     string `json:"id"`
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// List is a list of url variants, stored in database as a JSON array.
+type List []Variant
+
+// Normalize validates the list and sets IDs of variants without ID to their letters by position: a, b, c and so on.
+// Empty list is valid, it means the url has no variants.
+func (l List) Normalize() error {
+	if len(l) == 0 {
+		return nil
+	}
+	if len(l) < 2 {
+		return ErrTooFewVariants
+	}
+	if len(l) > MaxVariants {
+		return ErrTooManyVariants
+	}
+
+	ids := make(map[string]bool, len(l))
+	for i := range l {
+		if l[i].ID == "" {
+			l[i].ID = positionID(i)
+		}
+		if !isValidID(l[i].ID) {
+			return ErrInvalidID
+		}
+		if ids[l[i].ID] {
+			return ErrDuplicateID
+		}
+		ids[l[i].ID] = true
+
+		if l[i].Weight < 1 || l[i].Weight > MaxWeight {
+			return ErrInvalidWeight
+		}
+	}
+
+	return nil
+}
+
+// TotalWeight returns the sum of weights of all variants.
+func (l List) TotalWeight() int {
+	total := 0
+	for _, v := range l {
+		total += v.Weight
+	}
+	return total
+}
+
+// Pick returns the variant, which n falls into, when variants split [0, TotalWeight()) by their weights.
+// Random n in this range picks every variant with probability proportional to its weight.
+// The function returns false for an empty list or n out of range.
+func (l List) Pick(n int) (Variant, bool) {
+	if n < 0 {
+		return Variant{}, false
+	}
+	for _, v := range l {
+		if n < v.Weight {
+			return v, true
+		}
+		n -= v.Weight
+	}
+	return Variant{}, false
+}
+
+// Get returns the variant with given ID.
+func (l List) Get(id string) (Variant, bool) {
+	for _, v := range l {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// Value implements driver.Valuer, so the list is stored as a JSON array.
+func (l List) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner, so the list is read from a JSON array.
+func (l *List) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, l)
+	case string:
+		return json.Unmarshal([]byte(src), l)
+	default:
+		return fmt.Errorf("variants: can't scan %T into list", src)
+	}
+}
+
+// positionID returns ID of the variant by its position: a for the first one, b for the second and so on.
+func positionID(i int) string {
+	if i < 26 {
+		return string(rune('a' + i))
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// isValidID reports whether the ID is safe to store in cookies and stats.
+func isValidID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package variants
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestListPick(t *testing.T) {
+	list := List{
+		{ID: "a", Url: "https://example.com/a", Weight: 70},
+		{ID: "b", Url: "https://example.com/b", Weight: 30},
+	}
+
+	tests := []struct {
+		name   string
+		n      int
+		wantID string
+		wantOk bool
+	}{
+		{name: "Start of the first variant", n: 0, wantID: "a", wantOk: true},
+		{name: "End of the first variant", n: 69, wantID: "a", wantOk: true},
+		{name: "Start of the second variant", n: 70, wantID: "b", wantOk: true},
+		{name: "End of the second variant", n: 99, wantID: "b", wantOk: true},
+		{name: "Out of range", n: 100},
+		{name: "Negative", n: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := list.Pick(tt.n)
+			if ok != tt.wantOk || v.ID != tt.wantID {
+				t.Errorf("Pick(%d) = %q, %v, want %q, %v", tt.n, v.ID, ok, tt.wantID, tt.wantOk)
+			}
+		})
+	}
+
+	if total := list.TotalWeight(); total != 100 {
+		t.Errorf("TotalWeight() = %d, want 100", total)
+	}
+}
+
+func TestListNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    List
+		want    List
+		wantErr error
+	}{
+		{
+			name: "Empty list",
+			list: List{},
+			want: List{},
+		},
+		{
+			name: "IDs are set by position",
+			list: List{{Url: "a", Weight: 1}, {ID: "control", Url: "b", Weight: 1}, {Url: "c", Weight: 1}},
+			want: List{{ID: "a", Url: "a", Weight: 1}, {ID: "control", Url: "b", Weight: 1}, {ID: "c", Url: "c", Weight: 1}},
+		},
+		{
+			name:    "One variant",
+			list:    List{{Url: "a", Weight: 1}},
+			wantErr: ErrTooFewVariants,
+		},
+		{
+			name:    "Too many variants",
+			list:    make(List, MaxVariants+1),
+			wantErr: ErrTooManyVariants,
+		},
+		{
+			name:    "Zero weight",
+			list:    List{{Url: "a", Weight: 1}, {Url: "b"}},
+			wantErr: ErrInvalidWeight,
+		},
+		{
+			name:    "Invalid ID",
+			list:    List{{ID: "a;b", Url: "a", Weight: 1}, {Url: "b", Weight: 1}},
+			wantErr: ErrInvalidID,
+		},
+		{
+			name:    "Duplicate ID",
+			list:    List{{ID: "b", Url: "a", Weight: 1}, {Url: "b", Weight: 1}},
+			wantErr: ErrDuplicateID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.list.Normalize()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.list, tt.want) {
+				t.Errorf("Normalize() = %+v, want %+v", tt.list, tt.want)
+			}
+		})
+	}
+}
+
+func TestListScan(t *testing.T) {
+	list := List{{ID: "a", Url: "https://example.com/a", Weight: 1}, {ID: "b", Url: "https://example.com/b", Weight: 2}}
+
+	value, err := list.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var got List
+	if err = got.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, list) {
+		t.Errorf("Scan() = %+v, want %+v", got, list)
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN max_redirects,
+    DROP COLUMN expires_at;
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    ADD COLUMN expires_at timestamptz DEFAULT NULL,
+    ADD COLUMN max_redirects int DEFAULT NULL CHECK (max_redirects > 0);
@@ -0,0 +1,2 @@
+ALTER TABLE urls
+    ADD COLUMN password_hash varchar(255) DEFAULT NULL;
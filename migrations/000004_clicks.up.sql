@@ -0,0 +1,15 @@
+CREATE TABLE clicks
+(
+    id bigserial PRIMARY KEY,
+    url_id uuid NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    referrer text NOT NULL DEFAULT '',
+    referrer_host varchar(255) NOT NULL DEFAULT '',
+    user_agent text NOT NULL DEFAULT '',
+    country varchar(2) NOT NULL DEFAULT '',
+    browser varchar(50) NOT NULL DEFAULT '',
+    os varchar(50) NOT NULL DEFAULT '',
+    device varchar(20) NOT NULL DEFAULT '',
+    created_at timestamptz DEFAULT now() NOT NULL
+);
+
+CREATE INDEX clicks_url_id_created_at_idx ON clicks (url_id, created_at);
@@ -0,0 +1,12 @@
+DROP INDEX urls_domain_id_short_url_key;
+DROP INDEX urls_short_url_key;
+
+-- urls of custom domains can't be moved to the default one, as their aliases may be taken there,
+-- so they are deleted with their domains
+DELETE FROM urls WHERE domain_id IS NOT NULL;
+
+ALTER TABLE urls
+    DROP COLUMN domain_id,
+    ADD CONSTRAINT urls_short_url_key UNIQUE (short_url);
+
+DROP TABLE domains;
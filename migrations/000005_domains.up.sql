@@ -0,0 +1,17 @@
+CREATE TABLE domains
+(
+    id uuid DEFAULT uuid_generate_v4() NOT NULL UNIQUE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    host varchar(253) NOT NULL UNIQUE,
+    verification_token varchar(64) NOT NULL,
+    verified_at timestamptz DEFAULT NULL,
+    created_at timestamp DEFAULT now() NOT NULL
+);
+
+ALTER TABLE urls
+    ADD COLUMN domain_id uuid DEFAULT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    DROP CONSTRAINT urls_short_url_key;
+
+-- aliases are unique per domain, urls without domain belong to the default one
+CREATE UNIQUE INDEX urls_short_url_key ON urls (short_url) WHERE domain_id IS NULL;
+CREATE UNIQUE INDEX urls_domain_id_short_url_key ON urls (domain_id, short_url) WHERE domain_id IS NOT NULL;
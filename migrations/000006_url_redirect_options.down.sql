@@ -0,0 +1,5 @@
+ALTER TABLE urls
+    DROP COLUMN redirect_code,
+    DROP COLUMN cache_control,
+    DROP COLUMN referrer_policy,
+    DROP COLUMN robots_tag;
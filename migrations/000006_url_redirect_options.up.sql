@@ -0,0 +1,5 @@
+ALTER TABLE urls
+    ADD COLUMN redirect_code smallint NOT NULL DEFAULT 308 CHECK (redirect_code IN (301, 302, 307, 308)),
+    ADD COLUMN cache_control varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN referrer_policy varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN robots_tag varchar(255) NOT NULL DEFAULT '';
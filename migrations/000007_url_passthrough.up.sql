@@ -0,0 +1,2 @@
+ALTER TABLE urls
+    ADD COLUMN query_passthrough varchar(10) NOT NULL DEFAULT 'off' CHECK (query_passthrough IN ('off', 'keep', 'override', 'append'));
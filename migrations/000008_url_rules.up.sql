@@ -0,0 +1,2 @@
+ALTER TABLE urls
+    ADD COLUMN rules jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,6 @@
+ALTER TABLE clicks
+    DROP COLUMN variant;
+
+ALTER TABLE urls
+    DROP COLUMN variants,
+    DROP COLUMN variants_sticky;
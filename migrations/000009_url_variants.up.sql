@@ -0,0 +1,6 @@
+ALTER TABLE urls
+    ADD COLUMN variants jsonb NOT NULL DEFAULT '[]',
+    ADD COLUMN variants_sticky boolean NOT NULL DEFAULT false;
+
+ALTER TABLE clicks
+    ADD COLUMN variant varchar(20) NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+ALTER TABLE urls
+    DROP COLUMN folder_id;
+
+DROP TABLE url_tags;
+DROP TABLE tags;
+DROP TABLE folders;
@@ -0,0 +1,35 @@
+CREATE TABLE folders
+(
+    id uuid DEFAULT uuid_generate_v4() NOT NULL UNIQUE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id uuid DEFAULT NULL REFERENCES folders(id) ON DELETE CASCADE,
+    name varchar(100) NOT NULL,
+    created_at timestamp DEFAULT now() NOT NULL
+);
+
+-- names are unique among folders of the same parent, folders without parent are top-level ones
+CREATE UNIQUE INDEX folders_user_id_name_key ON folders (user_id, name) WHERE parent_id IS NULL;
+CREATE UNIQUE INDEX folders_parent_id_name_key ON folders (parent_id, name) WHERE parent_id IS NOT NULL;
+
+CREATE TABLE tags
+(
+    id uuid DEFAULT uuid_generate_v4() NOT NULL UNIQUE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(50) NOT NULL,
+    created_at timestamp DEFAULT now() NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE url_tags
+(
+    url_id uuid NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    tag_id uuid NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX url_tags_tag_id_idx ON url_tags (tag_id);
+
+ALTER TABLE urls
+    ADD COLUMN folder_id uuid DEFAULT NULL REFERENCES folders(id) ON DELETE SET NULL;
+
+CREATE INDEX urls_folder_id_idx ON urls (folder_id);
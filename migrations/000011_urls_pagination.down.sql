@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS urls_user_id_short_url_idx;
+DROP INDEX IF EXISTS urls_user_id_redirects_idx;
+DROP INDEX IF EXISTS urls_user_id_created_at_idx;
+
+ALTER TABLE urls
+    ALTER COLUMN redirects DROP NOT NULL;
@@ -0,0 +1,7 @@
+UPDATE urls SET redirects = 0 WHERE redirects IS NULL;
+ALTER TABLE urls
+    ALTER COLUMN redirects SET NOT NULL;
+
+CREATE INDEX urls_user_id_created_at_idx ON urls (user_id, created_at, id);
+CREATE INDEX urls_user_id_redirects_idx ON urls (user_id, redirects, id);
+CREATE INDEX urls_user_id_short_url_idx ON urls (user_id, short_url, id);
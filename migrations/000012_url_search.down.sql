@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS urls_short_url_trgm_idx;
+DROP INDEX IF EXISTS urls_search_idx;
+
+ALTER TABLE urls
+    DROP COLUMN title,
+    DROP COLUMN notes;
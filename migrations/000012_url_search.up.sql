@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE urls
+    ADD COLUMN title varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN notes varchar(2000) NOT NULL DEFAULT '';
+
+-- The expression must be the same as searchDocument in the user repository, otherwise the index isn't used.
+CREATE INDEX urls_search_idx ON urls USING gin ((
+    setweight(to_tsvector('simple', short_url), 'A') ||
+    setweight(to_tsvector('simple', title), 'A') ||
+    setweight(to_tsvector('simple', notes), 'B') ||
+    setweight(to_tsvector('simple', regexp_replace(long_url, '[^[:alnum:]]+', ' ', 'g')), 'C')
+));
+CREATE INDEX urls_short_url_trgm_idx ON urls USING gin (short_url gin_trgm_ops);
@@ -0,0 +1 @@
+CREATE SEQUENCE urls_alias_seq;
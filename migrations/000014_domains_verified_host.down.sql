@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS domains_user_id_host_key;
+DROP INDEX IF EXISTS domains_verified_host_key;
+
+-- keep the verified or the earliest claim of every host
+DELETE FROM domains d
+WHERE EXISTS (
+    SELECT 1 FROM domains o
+    WHERE o.host = d.host AND o.id <> d.id
+      AND (o.verified_at IS NOT NULL OR (d.verified_at IS NULL AND (o.created_at, o.id) < (d.created_at, d.id)))
+);
+
+ALTER TABLE domains ADD CONSTRAINT domains_host_key UNIQUE (host);
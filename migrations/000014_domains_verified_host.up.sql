@@ -0,0 +1,5 @@
+-- hosts are taken by verified domains only, so not verified claims can't squat them
+ALTER TABLE domains DROP CONSTRAINT domains_host_key;
+
+CREATE UNIQUE INDEX domains_verified_host_key ON domains (host) WHERE verified_at IS NOT NULL;
+CREATE UNIQUE INDEX domains_user_id_host_key ON domains (user_id, host);